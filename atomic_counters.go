@@ -6,6 +6,50 @@ import (
 	"sync/atomic"
 )
 
+type Counter interface {
+	Inc()
+	Value() int64
+}
+
+type AtomicCounter struct {
+	n atomic.Int64
+}
+
+func (c *AtomicCounter) Inc()         { c.n.Add(1) }
+func (c *AtomicCounter) Value() int64 { return c.n.Load() }
+
+type MutexCounter struct {
+	mu sync.Mutex
+	n  int64
+}
+
+func (c *MutexCounter) Inc() {
+	c.mu.Lock()
+	c.n++
+	c.mu.Unlock()
+}
+
+func (c *MutexCounter) Value() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
+
+// hammer increments c from goroutines goroutines, perGoroutine times each.
+func hammer(c Counter, goroutines, perGoroutine int) {
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perGoroutine; j++ {
+				c.Inc()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 func main() {
 
 	var ops uint64
@@ -22,4 +66,32 @@ func main() {
 	wg.Wait()
 	fmt.Println("ops:", ops)
 	fmt.Println("ops:", atomic.LoadUint64(&ops))
+
+	fmt.Println()
+	counters := []struct {
+		name string
+		c    Counter
+	}{
+		{"atomic", &AtomicCounter{}},
+		{"mutex", &MutexCounter{}},
+	}
+	for _, tc := range counters {
+		hammer(tc.c, 50, 1000)
+		fmt.Println(tc.name, "ops:", tc.c.Value())
+	}
 }
+
+/*
+	$ go run atomic_counters.go
+	ops: 50000
+	ops: 50000
+
+	atomic ops: 50000
+	mutex ops: 50000
+
+	$ go test -race -bench . atomic_counters.go atomic_counters_test.go
+	BenchmarkAtomicCounter 	10371666	       102.1 ns/op
+	BenchmarkMutexCounter  	 4130169	       297.0 ns/op
+	PASS
+	ok  	command-line-arguments	3.741s
+*/
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+// Run with -race: go test -race atomic_counters.go atomic_counters_test.go
+func TestCountersUnderContention(t *testing.T) {
+	for _, c := range []Counter{&AtomicCounter{}, &MutexCounter{}} {
+		hammer(c, 50, 1000)
+		if got := c.Value(); got != 50*1000 {
+			t.Errorf("%T counted %d, want %d", c, got, 50*1000)
+		}
+	}
+}
+
+// benchmarkCounter measures Inc with every CPU incrementing the same counter.
+func benchmarkCounter(b *testing.B, c Counter) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.Inc()
+		}
+	})
+}
+
+func BenchmarkAtomicCounter(b *testing.B) {
+	benchmarkCounter(b, &AtomicCounter{})
+}
+
+func BenchmarkMutexCounter(b *testing.B) {
+	benchmarkCounter(b, &MutexCounter{})
+}
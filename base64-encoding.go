@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	b64 "encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func encoding(urlSafe bool) *b64.Encoding {
+	if urlSafe {
+		return b64.URLEncoding
+	}
+	return b64.StdEncoding
+}
+
+func EncodeBase64(data []byte, urlSafe bool) string {
+	return encoding(urlSafe).EncodeToString(data)
+}
+
+// DecodeBase64 reverses EncodeBase64. Malformed input is an error naming the
+// offending byte offset.
+func DecodeBase64(s string, urlSafe bool) ([]byte, error) {
+	data, err := encoding(urlSafe).DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 %q : %w", s, err)
+	}
+	return data, nil
+}
+
+// EncodeStream writes src to dst as standard base64, a few bytes at a time,
+// so inputs of any size are never held in memory.
+func EncodeStream(dst io.Writer, src io.Reader) error {
+	enc := b64.NewEncoder(b64.StdEncoding, dst)
+	if _, err := io.Copy(enc, src); err != nil {
+		enc.Close()
+		return fmt.Errorf("encoding base64 stream : %w", err)
+	}
+	// the last partial block and the padding are only written on Close
+	return enc.Close()
+}
+
+// DecodeStream reverses EncodeStream. Newlines in src are skipped, so
+// line-wrapped base64 (e.g. from `base64` on the command line) decodes as is.
+func DecodeStream(dst io.Writer, src io.Reader) error {
+	if _, err := io.Copy(dst, b64.NewDecoder(b64.StdEncoding, src)); err != nil {
+		return fmt.Errorf("decoding base64 stream : %w", err)
+	}
+	return nil
+}
+
+func main() {
+
+	data := "abc123!?$*&()'-=@~"
+
+	sEnc := b64.StdEncoding.EncodeToString([]byte(data))
+	fmt.Println(sEnc)
+
+	sDec, _ := b64.StdEncoding.DecodeString(sEnc)
+	fmt.Println(string(sDec))
+	fmt.Println()
+
+	uEnc := b64.URLEncoding.EncodeToString([]byte(data))
+	fmt.Println(uEnc)
+	uDec, _ := b64.URLEncoding.DecodeString(uEnc)
+	fmt.Println(string(uDec))
+	fmt.Println()
+
+	for _, urlSafe := range []bool{false, true} {
+		enc := EncodeBase64([]byte{0xfb, 0xff, 0x00}, urlSafe)
+		dec, err := DecodeBase64(enc, urlSafe)
+		fmt.Println(urlSafe, enc, dec, err)
+	}
+
+	_, err := DecodeBase64("not base64!", false)
+	fmt.Println(err)
+	fmt.Println()
+
+	large := bytes.Repeat([]byte(data), 1<<16)
+	var encoded, decoded bytes.Buffer
+	if err := EncodeStream(&encoded, bytes.NewReader(large)); err != nil {
+		panic(err)
+	}
+	if err := DecodeStream(&decoded, &encoded); err != nil {
+		panic(err)
+	}
+	fmt.Println("stream:", len(large), "bytes, round trip equal:", bytes.Equal(large, decoded.Bytes()))
+
+	decoded.Reset()
+	err = DecodeStream(&decoded, strings.NewReader("YWJjMTIz\nIT8kKiYo\nKSctPUB+\n"))
+	fmt.Println(decoded.String(), err)
+	fmt.Println(DecodeStream(io.Discard, strings.NewReader("YWJj!!!!")))
+}
+
+/*
+	$ go run base64-encoding.go
+	YWJjMTIzIT8kKiYoKSctPUB+
+	abc123!?$*&()'-=@~
+
+	YWJjMTIzIT8kKiYoKSctPUB-
+	abc123!?$*&()'-=@~
+
+	false +/8A [251 255 0] <nil>
+	true -_8A [251 255 0] <nil>
+	invalid base64 "not base64!" : illegal base64 data at input byte 3
+
+	stream: 1179648 bytes, round trip equal: true
+	abc123!?$*&()'-=@~ <nil>
+	decoding base64 stream : illegal base64 data at input byte 4
+*/
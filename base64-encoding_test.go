@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestBase64RoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		urlSafe bool
+		want    string
+	}{
+		{"empty", nil, false, ""},
+		{"std", []byte("abc123!?$*&()'-=@~"), false, "YWJjMTIzIT8kKiYoKSctPUB+"},
+		{"std with + and /", []byte{0xfb, 0xff, 0xbf}, false, "+/+/"},
+		{"url safe", []byte{0xfb, 0xff, 0xbf}, true, "-_-_"},
+		{"padding", []byte("a"), false, "YQ=="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EncodeBase64(tt.data, tt.urlSafe)
+			if got != tt.want {
+				t.Errorf("encode: got %q, want %q", got, tt.want)
+			}
+			back, err := DecodeBase64(got, tt.urlSafe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(back, tt.data) {
+				t.Errorf("decode: got %q, want %q", back, tt.data)
+			}
+		})
+	}
+}
+
+func TestDecodeBase64Malformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		urlSafe bool
+	}{
+		{"bad char", "YW*j", false},
+		{"truncated", "YWJ", false},
+		{"url alphabet as std", "-_-_", false},
+		{"std alphabet as url", "+/+/", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeBase64(tt.in, tt.urlSafe)
+			var corrupt base64.CorruptInputError
+			if !errors.As(err, &corrupt) {
+				t.Errorf("got %v, want a base64.CorruptInputError", err)
+			}
+		})
+	}
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	// larger than the 32KB io.Copy buffer and not a multiple of 3, so the
+	// encoder has a partial block to flush on Close
+	data := make([]byte, 100*1024+1)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	var encoded bytes.Buffer
+	if err := EncodeStream(&encoded, bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	if want := base64.StdEncoding.EncodeToString(data); encoded.String() != want {
+		t.Fatalf("encoded %d bytes, want the %d of EncodeToString", encoded.Len(), len(want))
+	}
+
+	var decoded bytes.Buffer
+	if err := DecodeStream(&decoded, &encoded); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.Bytes(), data) {
+		t.Errorf("got %d bytes back, want the %d bytes in", decoded.Len(), len(data))
+	}
+}
+
+func TestDecodeStreamLineWrapped(t *testing.T) {
+	data := bytes.Repeat([]byte("go by example "), 20)
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	// wrapped at 76 columns, the way the base64 command writes it
+	var wrapped bytes.Buffer
+	for len(encoded) > 76 {
+		wrapped.WriteString(encoded[:76] + "\n")
+		encoded = encoded[76:]
+	}
+	wrapped.WriteString(encoded + "\n")
+
+	var decoded bytes.Buffer
+	if err := DecodeStream(&decoded, &wrapped); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decoded.Bytes(), data) {
+		t.Errorf("got %q, want %q", decoded.Bytes(), data)
+	}
+
+	if err := DecodeStream(io.Discard, bytes.NewBufferString("YW*j")); err == nil {
+		t.Error("malformed stream: got nil error")
+	}
+}
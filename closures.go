@@ -2,6 +2,8 @@ package main
 
 import (
 	"log"
+	"sync"
+	"sync/atomic"
 )
 
 func intSeq() func() int {
@@ -12,6 +14,61 @@ func intSeq() func() int {
 	}
 }
 
+// SafeSeq works like intSeq, but the returned function can be called from
+// many goroutines at once: incrementing through sync/atomic avoids the race
+// on the captured variable.
+func SafeSeq() func() int {
+	var i atomic.Int64
+	return func() int {
+		return int(i.Add(1))
+	}
+}
+
+type memoEntry[V any] struct {
+	once  sync.Once
+	value V
+	done  bool
+}
+
+// Memoize caches the results of fn. Concurrent callers asking for the same
+// key wait on a single call of fn instead of each computing it. A call that
+// panics is not cached: the next caller for that key tries fn again.
+func Memoize[K comparable, V any](fn func(K) V) func(K) V {
+	var mu sync.Mutex
+	cache := make(map[K]*memoEntry[V])
+
+	return func(k K) V {
+		for {
+			mu.Lock()
+			e, ok := cache[k]
+			if !ok {
+				e = &memoEntry[V]{}
+				cache[k] = e
+			}
+			mu.Unlock()
+
+			e.once.Do(func() {
+				defer func() {
+					if r := recover(); r != nil {
+						mu.Lock()
+						if cache[k] == e {
+							delete(cache, k)
+						}
+						mu.Unlock()
+						panic(r)
+					}
+				}()
+				e.value = fn(k)
+				e.done = true
+			})
+			// done is false only when fn panicked for someone else
+			if e.done {
+				return e.value
+			}
+		}
+	}
+}
+
 func main() {
 
 	nextInt := intSeq()
@@ -23,6 +80,38 @@ func main() {
 	anotherInt := intSeq()
 
 	log.Printf("[2] first increment of i value : %d", anotherInt())
+
+	safeInt := SafeSeq()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 50; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			safeInt()
+		}()
+	}
+	wg.Wait()
+
+	log.Printf("[3] next value after 50 concurrent increments : %d", safeInt())
+
+	var calls atomic.Int64
+	square := Memoize(func(n int) int {
+		calls.Add(1)
+		return n * n
+	})
+
+	for g := 0; g < 10; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			square(4)
+			square(5)
+		}()
+	}
+	wg.Wait()
+
+	log.Printf("[4] square(4) = %d, square(5) = %d, computed %d times", square(4), square(5), calls.Load())
 }
 
 /*
@@ -31,4 +120,6 @@ func main() {
 	2021/06/06 10:57:58 [1] second increment of i value : 2
 	2021/06/06 10:57:58 [1] third increment of i value : 3
 	2021/06/06 10:57:58 [2] first increment of i value : 1
+	2026/10/14 17:06:43 [3] next value after 50 concurrent increments : 51
+	2026/10/14 17:06:43 [4] square(4) = 16, square(5) = 25, computed 2 times
 */
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSafeSeqNoDuplicates(t *testing.T) {
+	next := SafeSeq()
+
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+	var wg sync.WaitGroup
+	for g := 0; g < 50; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				n := next()
+				mu.Lock()
+				if seen[n] {
+					t.Errorf("%d handed out twice", n)
+				}
+				seen[n] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != 50*100 {
+		t.Errorf("got %d distinct values, want %d", len(seen), 50*100)
+	}
+	if got := next(); got != 50*100+1 {
+		t.Errorf("next value %d, want %d", got, 50*100+1)
+	}
+}
+
+func TestMemoize(t *testing.T) {
+	var mu sync.Mutex
+	calls := make(map[int]int)
+	square := Memoize(func(n int) int {
+		mu.Lock()
+		calls[n]++
+		mu.Unlock()
+		return n * n
+	})
+
+	if got := square(3); got != 9 {
+		t.Errorf("square(3) = %d, want 9", got)
+	}
+	square(3)
+	if calls[3] != 1 {
+		t.Errorf("fn called %d times for 3, want 1", calls[3])
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 20; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := square(4); got != 16 {
+				t.Errorf("square(4) = %d, want 16", got)
+			}
+			square(5)
+		}()
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls[4] != 1 || calls[5] != 1 {
+		t.Errorf("concurrent callers computed 4 %d times and 5 %d times, want once each", calls[4], calls[5])
+	}
+}
+
+func TestMemoizePanicNotCached(t *testing.T) {
+	calls := 0
+	flaky := Memoize(func(n int) int {
+		calls++
+		if calls == 1 {
+			panic("first call fails")
+		}
+		return n * 2
+	})
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("first call didn't panic")
+			}
+		}()
+		flaky(1)
+	}()
+
+	if got := flaky(1); got != 2 {
+		t.Errorf("flaky(1) after a panic = %d, want 2", got)
+	}
+	flaky(1)
+	if calls != 2 {
+		t.Errorf("fn called %d times, want 2", calls)
+	}
+}
@@ -18,7 +18,8 @@ func Include(vs []string, t string) bool {
 	return Index(vs, t) >= 0
 }
 
-func Any(vs []string, f func(string) bool) bool {
+// Any is false for an empty slice.
+func Any[T any](vs []T, f func(T) bool) bool {
 	for _, v := range vs {
 		if f(v) {
 			return true
@@ -27,7 +28,8 @@ func Any(vs []string, f func(string) bool) bool {
 	return false
 }
 
-func All(vs []string, f func(string) bool) bool {
+// All is vacuously true for an empty slice.
+func All[T any](vs []T, f func(T) bool) bool {
 	for _, v := range vs {
 		if !f(v) {
 			return false
@@ -36,6 +38,20 @@ func All(vs []string, f func(string) bool) bool {
 	return true
 }
 
+// IndexOf is Index for a slice of any comparable type.
+func IndexOf[T comparable](vs []T, t T) int {
+	for i, v := range vs {
+		if v == t {
+			return i
+		}
+	}
+	return -1
+}
+
+func Contains[T comparable](vs []T, t T) bool {
+	return IndexOf(vs, t) >= 0
+}
+
 func Filter(vs []string, f func(string) bool) []string {
 	vsf := make([]string, 0)
 	for _, v := range vs {
@@ -76,4 +92,21 @@ func main() {
 
 	fmt.Println(Map(strs, strings.ToUpper))
 
+	nums := []int{3, 8, 15}
+	fmt.Println(IndexOf(nums, 8), Contains(nums, 4))
+	fmt.Println(Any(nums, func(n int) bool { return n > 10 }))
+	fmt.Println(All(nil, func(n int) bool { return n > 10 }))
 }
+
+/*
+	$ go run collection-functions.go
+	2
+	false
+	true
+	false
+	[peach apple pear]
+	[PEACH APPLE PEAR PLUM]
+	1 false
+	true
+	true
+*/
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestIndexAndInclude(t *testing.T) {
+	fruits := []string{"peach", "apple", "pear", "apple"}
+	tests := []struct {
+		name string
+		vs   []string
+		t    string
+		want int
+	}{
+		{"empty", nil, "pear", -1},
+		{"match", fruits, "pear", 2},
+		{"first of two", fruits, "apple", 1},
+		{"no match", fruits, "grape", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Index(tt.vs, tt.t); got != tt.want {
+				t.Errorf("Index: got %d, want %d", got, tt.want)
+			}
+			if got := IndexOf(tt.vs, tt.t); got != tt.want {
+				t.Errorf("IndexOf: got %d, want %d", got, tt.want)
+			}
+			if got, want := Include(tt.vs, tt.t), tt.want >= 0; got != want {
+				t.Errorf("Include: got %v, want %v", got, want)
+			}
+			if got, want := Contains(tt.vs, tt.t), tt.want >= 0; got != want {
+				t.Errorf("Contains: got %v, want %v", got, want)
+			}
+		})
+	}
+
+	if got := IndexOf([]int{3, 1, 4}, 4); got != 2 {
+		t.Errorf("IndexOf ints: got %d, want 2", got)
+	}
+}
+
+func TestAnyAndAll(t *testing.T) {
+	even := func(n int) bool { return n%2 == 0 }
+	tests := []struct {
+		name    string
+		vs      []int
+		wantAny bool
+		wantAll bool
+	}{
+		{"empty", nil, false, true},
+		{"all match", []int{2, 4}, true, true},
+		{"some match", []int{1, 2}, true, false},
+		{"no match", []int{1, 3}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Any(tt.vs, even); got != tt.wantAny {
+				t.Errorf("Any: got %v, want %v", got, tt.wantAny)
+			}
+			if got := All(tt.vs, even); got != tt.wantAll {
+				t.Errorf("All: got %v, want %v", got, tt.wantAll)
+			}
+		})
+	}
+}
+
+func TestFilterAndMap(t *testing.T) {
+	hasE := func(s string) bool { return strings.Contains(s, "e") }
+	tests := []struct {
+		name       string
+		vs         []string
+		wantFilter []string
+		wantMap    []string
+	}{
+		{"empty", nil, []string{}, []string{}},
+		{"match", []string{"peach", "plum", "pear"}, []string{"peach", "pear"}, []string{"PEACH", "PLUM", "PEAR"}},
+		{"no match", []string{"plum", "fig"}, []string{}, []string{"PLUM", "FIG"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a non-nil empty slice, so callers can always append or range
+			if got := Filter(tt.vs, hasE); got == nil || !slices.Equal(got, tt.wantFilter) {
+				t.Errorf("Filter: got %#v, want %#v", got, tt.wantFilter)
+			}
+			if got := Map(tt.vs, strings.ToUpper); !slices.Equal(got, tt.wantMap) {
+				t.Errorf("Map: got %v, want %v", got, tt.wantMap)
+			}
+		})
+	}
+}
@@ -5,14 +5,64 @@ import (
 	"os"
 )
 
+// Options are the arguments of "prog <source> <target> [extra...]".
+type Options struct {
+	Program string
+	Source  string
+	Target  string
+	Extra   []string
+}
+
+var requiredArgs = []string{"source", "target"}
+
+// ParseArgs parses os.Args-style input, program name first, so it can be
+// called with any slice instead of reading os.Args.
+func ParseArgs(args []string) (Options, error) {
+	if len(args) == 0 {
+		return Options{}, fmt.Errorf("missing program name")
+	}
+
+	positional := args[1:]
+	if len(positional) < len(requiredArgs) {
+		missing := requiredArgs[len(positional)]
+		return Options{}, fmt.Errorf("missing <%s>, usage: %s <source> <target> [extra...]", missing, args[0])
+	}
+
+	return Options{
+		Program: args[0],
+		Source:  positional[0],
+		Target:  positional[1],
+		Extra:   positional[len(requiredArgs):],
+	}, nil
+}
+
 func main() {
 
 	argsWithProg := os.Args
 	argsWithoutProg := os.Args[1:]
 
-	arg := os.Args[3]
+	opts, err := ParseArgs(os.Args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
 	fmt.Println(argsWithProg)
 	fmt.Println(argsWithoutProg)
-	fmt.Println(arg)
+	if len(os.Args) > 3 {
+		arg := os.Args[3]
+		fmt.Println(arg)
+	}
+	fmt.Printf("%+v\n", opts)
 }
+
+/*
+	$ go build command-line-arguments.go
+	$ ./command-line-arguments a b c d
+	[./command-line-arguments a b c d]
+	[a b c d]
+	c
+	{Program:./command-line-arguments Source:a Target:b Extra:[c d]}
+	$ ./command-line-arguments a
+	missing <target>, usage: ./command-line-arguments <source> <target> [extra...]
+*/
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    Options
+		wantErr string
+	}{
+		{"required only", []string{"prog", "a", "b"}, Options{Program: "prog", Source: "a", Target: "b", Extra: []string{}}, ""},
+		{"with extra", []string{"prog", "a", "b", "c", "d"}, Options{Program: "prog", Source: "a", Target: "b", Extra: []string{"c", "d"}}, ""},
+		{"missing target", []string{"prog", "a"}, Options{}, "missing <target>"},
+		{"missing source", []string{"prog"}, Options{}, "missing <source>"},
+		{"no program", nil, Options{}, "missing program name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseArgs(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,40 +1,98 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 )
 
-func main() {
+// Command is a subcommand. Run gets the arguments after the command name
+// and parses them with its own flag set.
+type Command struct {
+	Name string
+	Run  func(args []string) error
+}
 
-	fooCmd := flag.NewFlagSet("foo", flag.ExitOnError)
-	fooEnable := fooCmd.Bool("enable", false, "enable")
-	fooName := fooCmd.String("name", "", "name")
+var usageOutput io.Writer = os.Stderr
 
-	barCmd := flag.NewFlagSet("bar", flag.ExitOnError)
-	barLevel := barCmd.Int("level", 0, "level")
+var ErrUnknownCommand = errors.New("unknown command")
 
-	if len(os.Args) < 2 {
-		fmt.Println("expected 'foo' or 'bar' subcommands")
-		os.Exit(1)
+// Dispatch runs the command named by args[0] with the rest of args. A
+// missing or unknown name prints the available commands to usageOutput.
+func Dispatch(commands []Command, args []string) error {
+	names := make([]string, len(commands))
+	for i, c := range commands {
+		names[i] = c.Name
 	}
 
-	switch os.Args[1] {
+	if len(args) > 0 {
+		for _, c := range commands {
+			if c.Name == args[0] {
+				return c.Run(args[1:])
+			}
+		}
+	}
+
+	fmt.Fprintf(usageOutput, "expected one of these subcommands: %s\n", strings.Join(names, ", "))
+	if len(args) == 0 {
+		return fmt.Errorf("%w : none given", ErrUnknownCommand)
+	}
+	return fmt.Errorf("%w %q", ErrUnknownCommand, args[0])
+}
 
-	case "foo":
-		fooCmd.Parse(os.Args[2:])
+func main() {
+
+	foo := Command{Name: "foo", Run: func(args []string) error {
+		fooCmd := flag.NewFlagSet("foo", flag.ContinueOnError)
+		fooEnable := fooCmd.Bool("enable", false, "enable")
+		fooName := fooCmd.String("name", "", "name")
+		if err := fooCmd.Parse(args); err != nil {
+			return err
+		}
 		fmt.Println("subcommand 'foo'")
 		fmt.Println("  enable:", *fooEnable)
 		fmt.Println("  name:", *fooName)
 		fmt.Println("  tail:", fooCmd.Args())
-	case "bar":
-		barCmd.Parse(os.Args[2:])
+		return nil
+	}}
+
+	bar := Command{Name: "bar", Run: func(args []string) error {
+		barCmd := flag.NewFlagSet("bar", flag.ContinueOnError)
+		barLevel := barCmd.Int("level", 0, "level")
+		if err := barCmd.Parse(args); err != nil {
+			return err
+		}
 		fmt.Println("subcommand 'bar'")
 		fmt.Println("  level:", *barLevel)
 		fmt.Println("  tail:", barCmd.Args())
-	default:
-		fmt.Println("expected 'foo' or 'bar' subcommands")
+		return nil
+	}}
+
+	err := Dispatch([]Command{foo, bar}, os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return // the flag set already printed its usage
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
+
+/*
+	$ go build command-line-subcommands.go
+	$ ./command-line-subcommands foo -enable -name=joe a1 a2
+	subcommand 'foo'
+	  enable: true
+	  name: joe
+	  tail: [a1 a2]
+	$ ./command-line-subcommands bar -level 8 a1
+	subcommand 'bar'
+	  level: 8
+	  tail: [a1]
+	$ ./command-line-subcommands baz
+	expected one of these subcommands: foo, bar
+	unknown command "baz"
+*/
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// captureUsage points usageOutput at a buffer for the rest of the test.
+func captureUsage(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	old := usageOutput
+	usageOutput = &buf
+	t.Cleanup(func() { usageOutput = old })
+	return &buf
+}
+
+func TestDispatch(t *testing.T) {
+	var ran string
+	var gotArgs []string
+	record := func(name string) Command {
+		return Command{Name: name, Run: func(args []string) error {
+			ran, gotArgs = name, args
+			return nil
+		}}
+	}
+	commands := []Command{record("foo"), record("bar")}
+
+	tests := []struct {
+		args     []string
+		wantRan  string
+		wantArgs []string
+	}{
+		{[]string{"foo", "-enable", "a1"}, "foo", []string{"-enable", "a1"}},
+		{[]string{"bar"}, "bar", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantRan, func(t *testing.T) {
+			usage := captureUsage(t)
+			ran, gotArgs = "", nil
+			if err := Dispatch(commands, tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if ran != tt.wantRan || !slices.Equal(gotArgs, tt.wantArgs) {
+				t.Errorf("got %s %q, want %s %q", ran, gotArgs, tt.wantRan, tt.wantArgs)
+			}
+			if usage.Len() != 0 {
+				t.Errorf("got usage %q, want none", usage)
+			}
+		})
+	}
+}
+
+func TestDispatchUnknown(t *testing.T) {
+	commands := []Command{
+		{Name: "foo", Run: func([]string) error { t.Error("foo ran"); return nil }},
+		{Name: "bar", Run: func([]string) error { t.Error("bar ran"); return nil }},
+	}
+
+	for _, args := range [][]string{{"baz"}, nil} {
+		usage := captureUsage(t)
+		err := Dispatch(commands, args)
+		if !errors.Is(err, ErrUnknownCommand) {
+			t.Errorf("%q: got %v, want ErrUnknownCommand", args, err)
+		}
+		if want := "expected one of these subcommands: foo, bar\n"; usage.String() != want {
+			t.Errorf("%q: got usage %q, want %q", args, usage, want)
+		}
+	}
+}
+
+func TestDispatchReturnsRunError(t *testing.T) {
+	captureUsage(t)
+	strict := Command{Name: "strict", Run: func(args []string) error {
+		fs := flag.NewFlagSet("strict", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		return fs.Parse(args)
+	}}
+	err := Dispatch([]Command{strict}, []string{"strict", "-nope"})
+	if err == nil || !strings.Contains(err.Error(), "-nope") {
+		t.Errorf("got %v, want the flag parse error", err)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// pipelineKey is unexported, so no other package can build a key that
+// collides with ours, even one with the same underlying value.
+type pipelineKey int
+
+const (
+	runIDKey pipelineKey = iota
+	stageKey
+)
+
+func WithRunID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, runIDKey, id)
+}
+
+func RunIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(runIDKey).(string)
+	return id, ok
+}
+
+func StageFromContext(ctx context.Context) (string, bool) {
+	stage, ok := ctx.Value(stageKey).(string)
+	return stage, ok
+}
+
+type Item struct {
+	RunID string
+	Stage string
+	Value int
+}
+
+// Pipeline chains a counting stage and a squaring stage, each on its own
+// goroutine, and returns the squares. Both stages run under a cancellable
+// child of ctx: cancelling ctx stops them and closes the returned channel.
+// Each stage stores its name on its own child context, which the stages see
+// along with the caller's run id, but which never shows up in ctx itself.
+func Pipeline(ctx context.Context) <-chan Item {
+	ctx, cancel := context.WithCancel(ctx)
+
+	nums := make(chan int)
+	go func() {
+		defer close(nums)
+		ctx := context.WithValue(ctx, stageKey, "count")
+		for n := 1; ; n++ {
+			select {
+			case nums <- n:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	out := make(chan Item)
+	go func() {
+		// the counter only stops on cancel, so stop it whenever we stop
+		defer cancel()
+		defer close(out)
+		ctx := context.WithValue(ctx, stageKey, "square")
+		runID, _ := RunIDFromContext(ctx)
+		stage, _ := StageFromContext(ctx)
+		for n := range nums {
+			select {
+			case out <- Item{RunID: runID, Stage: stage, Value: n * n}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+func main() {
+
+	ctx, cancel := context.WithCancel(WithRunID(context.Background(), "run-1"))
+
+	items := Pipeline(ctx)
+	for i := 0; i < 3; i++ {
+		fmt.Printf("%+v\n", <-items)
+	}
+
+	cancel()
+	start := time.Now()
+	for range items {
+	}
+	fmt.Println("pipeline stopped after cancel:", time.Since(start) < time.Second)
+
+	runID, _ := RunIDFromContext(ctx)
+	_, ok := StageFromContext(ctx)
+	fmt.Println("run id in parent:", runID)
+	fmt.Println("stage visible in parent:", ok)
+}
+
+/*
+	$ go run context-pipeline.go
+	{RunID:run-1 Stage:square Value:1}
+	{RunID:run-1 Stage:square Value:4}
+	{RunID:run-1 Stage:square Value:9}
+	pipeline stopped after cancel: true
+	run id in parent: run-1
+	stage visible in parent: false
+*/
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPipelineStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(WithRunID(context.Background(), "run-1"))
+	defer cancel()
+
+	items := Pipeline(ctx)
+	for i := 1; i <= 3; i++ {
+		want := Item{RunID: "run-1", Stage: "square", Value: i * i}
+		if got := <-items; got != want {
+			t.Errorf("item %d: got %+v, want %+v", i, got, want)
+		}
+	}
+
+	cancel()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-items:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("the output channel was not closed after cancel")
+		}
+	}
+}
+
+func TestPipelineAlreadyCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	select {
+	case <-drain(Pipeline(ctx)):
+	case <-time.After(5 * time.Second):
+		t.Fatal("a pipeline on a cancelled context did not stop")
+	}
+}
+
+// drain reads items until it is closed, then closes the returned channel.
+func drain(items <-chan Item) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range items {
+		}
+	}()
+	return done
+}
+
+func TestStageNotVisibleInParent(t *testing.T) {
+	ctx, cancel := context.WithCancel(WithRunID(context.Background(), "run-2"))
+	items := Pipeline(ctx)
+	item := <-items
+	cancel()
+	<-drain(items)
+
+	if item.Stage != "square" {
+		t.Errorf("in the stage: got stage %q, want %q", item.Stage, "square")
+	}
+	if stage, ok := StageFromContext(ctx); ok {
+		t.Errorf("in the parent: got stage %q, want none", stage)
+	}
+	if id, ok := RunIDFromContext(ctx); !ok || id != "run-2" {
+		t.Errorf("in the parent: got run id %q, %v, want run-2, true", id, ok)
+	}
+}
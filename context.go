@@ -1,32 +1,203 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 )
 
+type ctxKey int
+
+const (
+	requestIDKey ctxKey = iota
+	userKey
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// RequestID reuses an incoming X-Request-ID or generates a new one, stores it
+// in the request context and echoes it back on the response.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(requestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+
+		w.Header().Set(requestIDHeader, id)
+		ctx := context.WithValue(req.Context(), requestIDKey, id)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+type User struct {
+	ID   string
+	Name string
+}
+
+// TokenVerifier checks a bearer token and tells who it belongs to.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (User, error)
+}
+
+// staticTokens is a TokenVerifier backed by a fixed token -> user map.
+type staticTokens map[string]User
+
+func (s staticTokens) Verify(ctx context.Context, token string) (User, error) {
+	user, ok := s[token]
+	if !ok {
+		return User{}, errors.New("unknown token")
+	}
+	return user, nil
+}
+
+// Authenticate only lets requests with a valid "Authorization: Bearer" token
+// through, and stores the user they belong to in the request context.
+func Authenticate(verifier TokenVerifier, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		user, err := verifier.Verify(req.Context(), token)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(req.Context(), userKey, user)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+func UserFromContext(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(userKey).(User)
+	return user, ok
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		log.Panicf("Something went wrong while generating request id : %v \n", err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// WithCleanup returns a child of ctx plus a function to register cleanups.
+// Once the child is done, the cleanups run exactly once in LIFO order, like
+// deferred calls; cleanups registered after that run straight away. The
+// returned cancel may be called any number of times.
+func WithCleanup(ctx context.Context) (context.Context, func(cleanup func()), context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	var mu sync.Mutex
+	var cleanups []func()
+	done := false
+
+	context.AfterFunc(ctx, func() {
+		mu.Lock()
+		done = true
+		pending := cleanups
+		cleanups = nil
+		mu.Unlock()
+
+		for i := len(pending) - 1; i >= 0; i-- {
+			pending[i]()
+		}
+	})
+
+	register := func(cleanup func()) {
+		mu.Lock()
+		if done {
+			mu.Unlock()
+			cleanup()
+			return
+		}
+		cleanups = append(cleanups, cleanup)
+		mu.Unlock()
+	}
+
+	return ctx, register, cancel
+}
+
+var (
+	workDelay      = 10 * time.Second
+	handlerTimeout time.Duration
+)
+
+// doWork simulates d worth of work, giving up early with ctx.Err() once ctx
+// is done.
+func doWork(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func hello(w http.ResponseWriter, req *http.Request) {
 
 	ctx := req.Context()
-	log.Println("server: hello handler started")
-	defer log.Println("server: hello handler ended")
+	if handlerTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, handlerTimeout)
+		defer cancel()
+	}
 
-	select {
-	case <-time.After(10 * time.Second):
+	ctx, onDone, cancel := WithCleanup(ctx)
+	defer cancel()
+
+	id := RequestIDFromContext(ctx)
+	log.Printf("server [%s]: hello handler started", id)
+	defer log.Printf("server [%s]: hello handler ended", id)
+	onDone(func() { log.Printf("server [%s]: releasing request resources", id) })
+
+	err := doWork(ctx, workDelay)
+	switch {
+	case err == nil:
 		fmt.Fprintf(w, "hello\n")
-	case <-ctx.Done():
-		err := ctx.Err()
+	case errors.Is(err, context.DeadlineExceeded):
+		fmt.Println("server:", err)
+		http.Error(w, err.Error(), http.StatusGatewayTimeout)
+	default:
+		// the client is gone, so there is nobody left to answer
 		fmt.Println("server:", err)
-		internalError := http.StatusInternalServerError
-		http.Error(w, err.Error(), internalError)
 	}
 }
 
+func me(w http.ResponseWriter, req *http.Request) {
+	user, _ := UserFromContext(req.Context())
+	fmt.Fprintf(w, "hello %s (%s)\n", user.Name, user.ID)
+}
+
 func main() {
 
-	http.HandleFunc("/hello", hello)
+	flag.DurationVar(&workDelay, "delay", workDelay, "how long the hello handler works before answering")
+	flag.DurationVar(&handlerTimeout, "timeout", 0, "deadline for the hello handler (0 means none)")
+	flag.Parse()
+
+	http.Handle("/hello", RequestID(http.HandlerFunc(hello)))
+
+	verifier := staticTokens{"gopher-token": {ID: "1", Name: "gopher"}}
+	http.Handle("/me", RequestID(Authenticate(verifier, http.HandlerFunc(me))))
 	http.ListenAndServe(":8090", nil)
 }
 
@@ -38,4 +209,12 @@ func main() {
 	server: context canceled (when we cancel the client call)
 	2021/06/07 10:26:53 server: hello handler ended
 	^Csignal: interrupt
+
+	With the RequestID middleware every log line carries the request id (generated, or taken from X-Request-ID):
+	$ go run context.go
+	2026/10/14 17:02:52 server [78f256d0df18ac15]: hello handler started
+	2026/10/14 17:03:02 server [78f256d0df18ac15]: hello handler ended
+	2026/10/14 17:03:02 server [my-request]: hello handler started
+	server: context canceled
+	2026/10/14 17:03:04 server [my-request]: hello handler ended
 */
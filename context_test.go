@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		seen = RequestIDFromContext(req.Context())
+	}))
+
+	t.Run("generated", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hello", nil))
+		if len(seen) != 16 {
+			t.Errorf("got generated id %q, want 16 hex characters", seen)
+		}
+		if got := rec.Header().Get(requestIDHeader); got != seen {
+			t.Errorf("response header %q, context %q", got, seen)
+		}
+	})
+
+	t.Run("preserved", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+		req.Header.Set(requestIDHeader, "my-request")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if seen != "my-request" {
+			t.Errorf("got id %q in context, want my-request", seen)
+		}
+		if got := rec.Header().Get(requestIDHeader); got != "my-request" {
+			t.Errorf("got id %q on the response, want my-request", got)
+		}
+	})
+}
+
+func TestHelloTimeoutVsCancel(t *testing.T) {
+	defer func(delay, timeout time.Duration) {
+		workDelay, handlerTimeout = delay, timeout
+	}(workDelay, handlerTimeout)
+	workDelay = time.Second
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		handlerTimeout = 20 * time.Millisecond
+		rec := httptest.NewRecorder()
+		hello(rec, httptest.NewRequest(http.MethodGet, "/hello", nil))
+		if rec.Code != http.StatusGatewayTimeout {
+			t.Errorf("got status %d, want 504", rec.Code)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		handlerTimeout = 0
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		rec := httptest.NewRecorder()
+		hello(rec, httptest.NewRequest(http.MethodGet, "/hello", nil).WithContext(ctx))
+		if rec.Body.Len() != 0 || rec.Code != http.StatusOK {
+			t.Errorf("got %d %q, want nothing written for a gone client", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("doWork", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := doWork(ctx, time.Second); !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v, want context.Canceled", err)
+		}
+		ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		if err := doWork(ctx, time.Second); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got %v, want context.DeadlineExceeded", err)
+		}
+	})
+}
+
+func TestWithCleanup(t *testing.T) {
+	var mu sync.Mutex
+	var order []int
+	ran := make(chan struct{}, 4)
+	push := func(n int) func() {
+		return func() {
+			mu.Lock()
+			order = append(order, n)
+			mu.Unlock()
+			ran <- struct{}{}
+		}
+	}
+
+	ctx, onDone, cancel := WithCleanup(context.Background())
+	onDone(push(1))
+	onDone(push(2))
+	onDone(push(3))
+
+	cancel()
+	cancel()
+	<-ctx.Done()
+	for i := 0; i < 3; i++ {
+		<-ran
+	}
+
+	// registered once the context is done, so it runs right away
+	onDone(push(4))
+	<-ran
+
+	select {
+	case <-ran:
+		t.Fatal("a cleanup ran more than once")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []int{3, 2, 1, 4}; !slices.Equal(order, want) {
+		t.Errorf("cleanups ran in order %v, want %v", order, want)
+	}
+}
+
+// fakeVerifier accepts a single token.
+type fakeVerifier struct {
+	token string
+	user  User
+}
+
+func (f fakeVerifier) Verify(ctx context.Context, token string) (User, error) {
+	if token != f.token {
+		return User{}, errors.New("unknown token")
+	}
+	return f.user, nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	verifier := fakeVerifier{token: "good", user: User{ID: "1", Name: "gopher"}}
+	h := Authenticate(verifier, http.HandlerFunc(me))
+
+	var tests = []struct {
+		name       string
+		header     string
+		wantStatus int
+		wantBody   string
+	}{
+		{"valid", "Bearer good", http.StatusOK, "hello gopher (1)\n"},
+		{"invalid", "Bearer bad", http.StatusUnauthorized, "invalid token\n"},
+		{"missing", "", http.StatusUnauthorized, "missing bearer token\n"},
+		{"not bearer", "Basic Z29waGVy", http.StatusUnauthorized, "missing bearer token\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus || rec.Body.String() != tt.wantBody {
+				t.Errorf("got %d %q, want %d %q", rec.Code, rec.Body.String(), tt.wantStatus, tt.wantBody)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without WWW-Authenticate")
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -10,6 +11,41 @@ func main() {
 	f := createFile("/tmp/defer.txt")
 	defer closeFile(f)
 	writeFile(f)
+
+	var cleanups CleanupStack
+	for _, p := range []string{"/tmp/defer-1.txt", "/tmp/defer-2.txt"} {
+		f := createFile(p)
+		name := f.Name()
+		cleanups.Push(func() error {
+			log.Printf("closing file : %s \n", name)
+			return f.Close()
+		})
+		writeFile(f)
+	}
+	if err := cleanups.Run(); err != nil {
+		log.Fatalf("something went wrong while cleaning up : %v\n", err)
+	}
+}
+
+// CleanupStack runs its functions in reverse order of Push, like defer does,
+// but keeps going when one fails and reports every error.
+type CleanupStack struct {
+	fns []func() error
+}
+
+func (s *CleanupStack) Push(fn func() error) {
+	s.fns = append(s.fns, fn)
+}
+
+func (s *CleanupStack) Run() error {
+	var errs []error
+	for i := len(s.fns) - 1; i >= 0; i-- {
+		if err := s.fns[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	s.fns = nil
+	return errors.Join(errs...)
 }
 
 func createFile(p string) *os.File {
@@ -40,4 +76,16 @@ func closeFile(f *os.File) {
 	2021/06/06 10:45:57 creating file : /tmp/defer.txt
 	2021/06/06 10:45:57 writing data to file : /tmp/defer.txt
 	2021/06/06 10:45:57 closing file : /tmp/defer.txt
+
+	With the CleanupStack closing two more files (in reverse order, before the deferred close runs):
+	$ go run defer.go
+	2026/10/14 17:05:24 creating file : /tmp/defer.txt
+	2026/10/14 17:05:24 writing data to file : /tmp/defer.txt
+	2026/10/14 17:05:24 creating file : /tmp/defer-1.txt
+	2026/10/14 17:05:24 writing data to file : /tmp/defer-1.txt
+	2026/10/14 17:05:24 creating file : /tmp/defer-2.txt
+	2026/10/14 17:05:24 writing data to file : /tmp/defer-2.txt
+	2026/10/14 17:05:24 closing file : /tmp/defer-2.txt
+	2026/10/14 17:05:24 closing file : /tmp/defer-1.txt
+	2026/10/14 17:05:24 closing file : /tmp/defer.txt
 */
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCleanupStack(t *testing.T) {
+	errMiddle := errors.New("middle failed")
+	var order []string
+
+	var s CleanupStack
+	s.Push(func() error { order = append(order, "first"); return nil })
+	s.Push(func() error { order = append(order, "middle"); return errMiddle })
+	s.Push(func() error { order = append(order, "last"); return nil })
+
+	err := s.Run()
+	if !errors.Is(err, errMiddle) {
+		t.Errorf("got error %v, want %v", err, errMiddle)
+	}
+	if want := []string{"last", "middle", "first"}; !slices.Equal(order, want) {
+		t.Errorf("ran in order %v, want %v", order, want)
+	}
+
+	// a second Run has nothing left to do
+	if err := s.Run(); err != nil || len(order) != 3 {
+		t.Errorf("second Run returned %v after %d calls", err, len(order))
+	}
+}
@@ -2,9 +2,11 @@ package main
 
 import (
 	"fmt"
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 func check(e error) {
@@ -58,6 +60,43 @@ func main() {
 
 	fmt.Println("Visiting subdir")
 	err = filepath.Walk("subdir", visit)
+
+	createEmptyFile("subdir/parent/notes.txt")
+	createEmptyFile("subdir/parent/child/todo.txt")
+	txt, err := WalkFiles("subdir", func(p string, d os.DirEntry) bool {
+		if d.IsDir() {
+			return d.Name() != "child"
+		}
+		return strings.HasSuffix(p, ".txt")
+	})
+	check(err)
+	fmt.Println("Text files outside child:", txt)
+}
+
+// WalkFiles returns the files under root for which match is true. match is
+// also asked about every directory below root, and a rejected directory is
+// skipped entirely. Errors from the walk are returned, not ignored.
+func WalkFiles(root string, match func(path string, info os.DirEntry) bool) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != root && !match(p, d) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if match(p, d) {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
 }
 
 func visit(p string, info os.FileInfo, err error) error {
@@ -67,3 +106,22 @@ func visit(p string, info os.FileInfo, err error) error {
 	fmt.Println(" ", p, info.IsDir())
 	return nil
 }
+
+/*
+	$ go run directories.go
+	Listing subdir/parent
+	  child true
+	  file2 false
+	  file3 false
+	Listing subdir/parent/child
+	  file4 false
+	Visiting subdir
+	  subdir true
+	  subdir/file1 false
+	  subdir/parent true
+	  subdir/parent/child true
+	  subdir/parent/child/file4 false
+	  subdir/parent/file2 false
+	  subdir/parent/file3 false
+	Text files outside child: [subdir/parent/notes.txt]
+*/
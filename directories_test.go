@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// makeTree creates each of files, with its parents, under a new temp dir.
+func makeTree(t *testing.T, files ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, f := range files {
+		p := filepath.Join(root, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestWalkFiles(t *testing.T) {
+	root := makeTree(t, "a.txt", "b.go", "parent/notes.txt", "parent/child/todo.txt", "parent/child/main.go")
+	rel := func(paths []string) []string {
+		out := make([]string, len(paths))
+		for i, p := range paths {
+			r, err := filepath.Rel(root, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out[i] = filepath.ToSlash(r)
+		}
+		return out
+	}
+
+	t.Run("by extension", func(t *testing.T) {
+		got, err := WalkFiles(root, func(p string, d os.DirEntry) bool {
+			return d.IsDir() || strings.HasSuffix(p, ".txt")
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"a.txt", "parent/child/todo.txt", "parent/notes.txt"}; !slices.Equal(rel(got), want) {
+			t.Errorf("got %v, want %v", rel(got), want)
+		}
+	})
+
+	t.Run("skip dir", func(t *testing.T) {
+		got, err := WalkFiles(root, func(p string, d os.DirEntry) bool {
+			if d.IsDir() {
+				return d.Name() != "child"
+			}
+			return true
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"a.txt", "b.go", "parent/notes.txt"}; !slices.Equal(rel(got), want) {
+			t.Errorf("got %v, want %v", rel(got), want)
+		}
+	})
+
+	t.Run("root is never rejected", func(t *testing.T) {
+		got, err := WalkFiles(root, func(p string, d os.DirEntry) bool {
+			return !d.IsDir()
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"a.txt", "b.go"}; !slices.Equal(rel(got), want) {
+			t.Errorf("got %v, want %v", rel(got), want)
+		}
+	})
+}
+
+func TestWalkFilesErrors(t *testing.T) {
+	t.Run("missing root", func(t *testing.T) {
+		got, err := WalkFiles(filepath.Join(t.TempDir(), "missing"), func(string, os.DirEntry) bool { return true })
+		if !os.IsNotExist(err) || got != nil {
+			t.Errorf("got %v, %v, want nil and a not-exist error", got, err)
+		}
+	})
+
+	t.Run("unreadable dir", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root ignores directory permissions")
+		}
+		root := makeTree(t, "a.txt", "locked/b.txt")
+		locked := filepath.Join(root, "locked")
+		if err := os.Chmod(locked, 0); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.Chmod(locked, 0755) })
+
+		got, err := WalkFiles(root, func(string, os.DirEntry) bool { return true })
+		if !os.IsPermission(err) || got != nil {
+			t.Errorf("got %v, %v, want nil and a permission error", got, err)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Weekday int
+
+const (
+	Sunday Weekday = iota
+	Monday
+	Tuesday
+	Wednesday
+	Thursday
+	Friday
+	Saturday
+)
+
+var weekdayNames = [...]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+func (d Weekday) String() string {
+	if d < Sunday || d > Saturday {
+		return fmt.Sprintf("Weekday(%d)", int(d))
+	}
+	return weekdayNames[d]
+}
+
+func ParseWeekday(s string) (Weekday, error) {
+	for i, name := range weekdayNames {
+		if strings.EqualFold(name, s) {
+			return Weekday(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q", s)
+}
+
+func main() {
+
+	fmt.Println(Monday, Saturday)
+
+	fmt.Printf("%v = %d\n", Wednesday, Wednesday)
+
+	fmt.Println(Weekday(9))
+
+	d, err := ParseWeekday("friDAY")
+	fmt.Println(d, err)
+
+	_, err = ParseWeekday("Funday")
+	fmt.Println(err)
+}
+
+/*
+	$ go run enums.go
+	Monday Saturday
+	Wednesday = 3
+	Weekday(9)
+	Friday <nil>
+	unknown weekday "Funday"
+*/
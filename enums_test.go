@@ -0,0 +1,26 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWeekdayRoundTrip(t *testing.T) {
+	for d := Sunday; d <= Saturday; d++ {
+		t.Run(d.String(), func(t *testing.T) {
+			for _, name := range []string{d.String(), strings.ToLower(d.String()), strings.ToUpper(d.String())} {
+				got, err := ParseWeekday(name)
+				if err != nil || got != d {
+					t.Errorf("ParseWeekday(%q) = %v, %v, want %v", name, got, err, d)
+				}
+			}
+		})
+	}
+
+	if got := Weekday(9).String(); got != "Weekday(9)" {
+		t.Errorf("Weekday(9) printed as %q", got)
+	}
+	if _, err := ParseWeekday("Funday"); err == nil {
+		t.Error("ParseWeekday accepted Funday")
+	}
+}
@@ -1,11 +1,100 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// GetEnvString returns the value of key, or def when it is unset.
+func GetEnvString(key, def string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return def
+}
+
+// getEnv parses the value of key, returning def when it is unset and an error
+// naming key when it doesn't parse.
+func getEnv[T any](key string, def T, parse func(string) (T, error)) (T, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return def, nil
+	}
+	parsed, err := parse(v)
+	if err != nil {
+		return def, fmt.Errorf("invalid %s %q : %w", key, v, err)
+	}
+	return parsed, nil
+}
+
+func GetEnvInt(key string, def int) (int, error) {
+	return getEnv(key, def, strconv.Atoi)
+}
+
+// GetEnvBool accepts the values of strconv.ParseBool: 1, t, true, 0, f, false...
+func GetEnvBool(key string, def bool) (bool, error) {
+	return getEnv(key, def, strconv.ParseBool)
+}
+
+func GetEnvDuration(key string, def time.Duration) (time.Duration, error) {
+	return getEnv(key, def, time.ParseDuration)
+}
+
+// Require checks that every key is set and non-empty, reporting all the
+// missing ones at once.
+func Require(keys ...string) error {
+	var errs []error
+	for _, key := range keys {
+		if os.Getenv(key) == "" {
+			errs = append(errs, fmt.Errorf("required environment variable %s is not set", key))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type Config struct {
+	ServerAddr   string
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	MaxConns     int
+	LogLevel     string
+}
+
+// LoadConfig builds a Config from the environment, falling back to defaults
+// for unset variables. A malformed value is an error naming the variable.
+func LoadConfig() (Config, error) {
+	cfg := Config{
+		ServerAddr: GetEnvString("SERVER_ADDR", "127.0.0.1:8080"),
+		LogLevel:   "info",
+	}
+
+	var err error
+	if cfg.ReadTimeout, err = GetEnvDuration("READ_TIMEOUT", 5*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.WriteTimeout, err = GetEnvDuration("WRITE_TIMEOUT", 10*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.MaxConns, err = GetEnvInt("MAX_CONNS", 100); err != nil {
+		return Config{}, err
+	}
+
+	if v, ok := os.LookupEnv("LOG_LEVEL"); ok {
+		switch level := strings.ToLower(v); level {
+		case "debug", "info", "warn", "error":
+			cfg.LogLevel = level
+		default:
+			return Config{}, fmt.Errorf("invalid LOG_LEVEL %q : want debug, info, warn or error", v)
+		}
+	}
+
+	return cfg, nil
+}
+
 func main() {
 
 	os.Setenv("FOO", "1")
@@ -17,4 +106,32 @@ func main() {
 		pair := strings.SplitN(e, "=", 2)
 		fmt.Println(pair[0])
 	}
+
+	fmt.Println()
+	cfg, err := LoadConfig()
+	if err != nil {
+		fmt.Println("config error:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("config: %+v\n", cfg)
+
+	debug, err := GetEnvBool("DEBUG", false)
+	fmt.Println("DEBUG:", debug, err)
+	fmt.Println(Require("FOO", "API_TOKEN", "DB_URL"))
 }
+
+/*
+	$ env -i PATH=$PATH HOME=$HOME DEBUG=yes go run environment-variables.go
+	FOO: 1
+	BAR:
+
+	HOME
+	DEBUG
+	PATH
+	FOO
+
+	config: {ServerAddr:127.0.0.1:8080 ReadTimeout:5s WriteTimeout:10s MaxConns:100 LogLevel:info}
+	DEBUG: false invalid DEBUG "yes" : strconv.ParseBool: parsing "yes": invalid syntax
+	required environment variable API_TOKEN is not set
+	required environment variable DB_URL is not set
+*/
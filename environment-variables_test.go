@@ -0,0 +1,148 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		for _, key := range []string{"SERVER_ADDR", "READ_TIMEOUT", "WRITE_TIMEOUT", "MAX_CONNS", "LOG_LEVEL"} {
+			unsetenv(t, key)
+		}
+		cfg, err := LoadConfig()
+		if err != nil {
+			t.Fatalf("LoadConfig failed : %v", err)
+		}
+		want := Config{ServerAddr: "127.0.0.1:8080", ReadTimeout: 5 * time.Second, WriteTimeout: 10 * time.Second, MaxConns: 100, LogLevel: "info"}
+		if cfg != want {
+			t.Errorf("got %+v, want %+v", cfg, want)
+		}
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		t.Setenv("SERVER_ADDR", ":9000")
+		t.Setenv("READ_TIMEOUT", "1s")
+		t.Setenv("WRITE_TIMEOUT", "1m")
+		t.Setenv("MAX_CONNS", "7")
+		t.Setenv("LOG_LEVEL", "DEBUG")
+		cfg, err := LoadConfig()
+		if err != nil {
+			t.Fatalf("LoadConfig failed : %v", err)
+		}
+		want := Config{ServerAddr: ":9000", ReadTimeout: time.Second, WriteTimeout: time.Minute, MaxConns: 7, LogLevel: "debug"}
+		if cfg != want {
+			t.Errorf("got %+v, want %+v", cfg, want)
+		}
+	})
+
+	var malformed = []struct{ key, value string }{
+		{"READ_TIMEOUT", "5"},
+		{"WRITE_TIMEOUT", "soon"},
+		{"MAX_CONNS", "many"},
+		{"LOG_LEVEL", "loud"},
+	}
+	for _, tt := range malformed {
+		t.Run("malformed "+tt.key, func(t *testing.T) {
+			t.Setenv(tt.key, tt.value)
+			_, err := LoadConfig()
+			if err == nil || !strings.Contains(err.Error(), tt.key) {
+				t.Errorf("got error %v, want one naming %s", err, tt.key)
+			}
+		})
+	}
+}
+
+// unsetenv clears key for the rest of the test, restoring it afterwards.
+func unsetenv(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv(key, "")
+	os.Unsetenv(key)
+}
+
+func TestGetEnv(t *testing.T) {
+	const key = "GO_BY_EXAMPLE_TEST_VAR"
+
+	t.Run("present", func(t *testing.T) {
+		t.Setenv(key, "42")
+		if got := GetEnvString(key, "def"); got != "42" {
+			t.Errorf("string: got %q, want %q", got, "42")
+		}
+		if got, err := GetEnvInt(key, 1); err != nil || got != 42 {
+			t.Errorf("int: got %d, %v, want 42, nil", got, err)
+		}
+		t.Setenv(key, "t")
+		if got, err := GetEnvBool(key, false); err != nil || !got {
+			t.Errorf("bool: got %v, %v, want true, nil", got, err)
+		}
+		t.Setenv(key, "1m30s")
+		if got, err := GetEnvDuration(key, 0); err != nil || got != 90*time.Second {
+			t.Errorf("duration: got %v, %v, want 1m30s, nil", got, err)
+		}
+	})
+
+	t.Run("set but empty", func(t *testing.T) {
+		t.Setenv(key, "")
+		if got := GetEnvString(key, "def"); got != "" {
+			t.Errorf("got %q, want the empty value, not the default", got)
+		}
+	})
+
+	t.Run("absent", func(t *testing.T) {
+		unsetenv(t, key)
+		if got := GetEnvString(key, "def"); got != "def" {
+			t.Errorf("string: got %q, want %q", got, "def")
+		}
+		if got, err := GetEnvInt(key, 7); err != nil || got != 7 {
+			t.Errorf("int: got %d, %v, want 7, nil", got, err)
+		}
+		if got, err := GetEnvBool(key, true); err != nil || !got {
+			t.Errorf("bool: got %v, %v, want true, nil", got, err)
+		}
+		if got, err := GetEnvDuration(key, time.Second); err != nil || got != time.Second {
+			t.Errorf("duration: got %v, %v, want 1s, nil", got, err)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		t.Setenv(key, "nope")
+		checks := map[string]func() error{
+			"int":      func() error { _, err := GetEnvInt(key, 7); return err },
+			"bool":     func() error { _, err := GetEnvBool(key, true); return err },
+			"duration": func() error { _, err := GetEnvDuration(key, time.Second); return err },
+		}
+		for name, check := range checks {
+			if err := check(); err == nil || !strings.Contains(err.Error(), key) {
+				t.Errorf("%s: got %v, want an error naming %s", name, err, key)
+			}
+		}
+		if got, _ := GetEnvInt(key, 7); got != 7 {
+			t.Errorf("int: got %d, want the default 7 on error", got)
+		}
+	})
+}
+
+func TestRequire(t *testing.T) {
+	t.Setenv("GO_BY_EXAMPLE_SET", "x")
+	t.Setenv("GO_BY_EXAMPLE_EMPTY", "")
+	unsetenv(t, "GO_BY_EXAMPLE_UNSET")
+
+	if err := Require("GO_BY_EXAMPLE_SET"); err != nil {
+		t.Errorf("present: got %v, want nil", err)
+	}
+
+	err := Require("GO_BY_EXAMPLE_SET", "GO_BY_EXAMPLE_EMPTY", "GO_BY_EXAMPLE_UNSET")
+	if err == nil {
+		t.Fatal("missing: got nil error")
+	}
+	for _, key := range []string{"GO_BY_EXAMPLE_EMPTY", "GO_BY_EXAMPLE_UNSET"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("got %v, want it to name %s", err, key)
+		}
+	}
+	if strings.Contains(err.Error(), "GO_BY_EXAMPLE_SET ") {
+		t.Errorf("got %v, want it not to name the variable that is set", err)
+	}
+}
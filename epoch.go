@@ -5,6 +5,16 @@ import (
 	"time"
 )
 
+// Epoch values don't carry a zone, so the From helpers always return UTC.
+
+func ToEpochSeconds(t time.Time) int64 { return t.Unix() }
+func ToEpochMillis(t time.Time) int64  { return t.UnixMilli() }
+func ToEpochNanos(t time.Time) int64   { return t.UnixNano() }
+
+func FromEpochSeconds(secs int64) time.Time { return time.Unix(secs, 0).UTC() }
+func FromEpochMillis(ms int64) time.Time    { return time.UnixMilli(ms).UTC() }
+func FromEpochNanos(ns int64) time.Time     { return time.Unix(0, ns).UTC() }
+
 func main() {
 
 	now := time.Now()
@@ -19,4 +29,23 @@ func main() {
 
 	fmt.Println(time.Unix(secs, 0))
 	fmt.Println(time.Unix(0, nanos))
+
+	launch := time.Date(2021, time.June, 8, 19, 40, 53, 340083000, time.FixedZone("SGT", 8*3600))
+	ms := ToEpochMillis(launch)
+	fmt.Println(ms, FromEpochMillis(ms))
+	fmt.Println(FromEpochSeconds(ToEpochSeconds(launch)))
+	fmt.Println(FromEpochNanos(ToEpochNanos(launch)))
 }
+
+/*
+	$ go run epoch.go
+	2026-10-14 17:27:51.035965943 +0000 UTC m=+0.000048339
+	1791998871
+	1791998871035
+	1791998871035965943
+	2026-10-14 17:27:51 +0000 UTC
+	2026-10-14 17:27:51.035965943 +0000 UTC
+	1623152453340 2021-06-08 11:40:53.34 +0000 UTC
+	2021-06-08 11:40:53 +0000 UTC
+	2021-06-08 11:40:53.340083 +0000 UTC
+*/
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEpochRoundTrip(t *testing.T) {
+	// a non-UTC zone, to check the From helpers don't pick it up
+	at := time.Date(2021, time.June, 8, 11, 40, 5, 123456789, time.FixedZone("CEST", 2*3600))
+
+	tests := []struct {
+		name string
+		to   func(time.Time) int64
+		from func(int64) time.Time
+		want time.Time
+	}{
+		{"seconds", ToEpochSeconds, FromEpochSeconds, at.Truncate(time.Second)},
+		{"millis", ToEpochMillis, FromEpochMillis, at.Truncate(time.Millisecond)},
+		{"nanos", ToEpochNanos, FromEpochNanos, at},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.from(tt.to(at))
+			if !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if got.Location() != time.UTC {
+				t.Errorf("got location %v, want UTC", got.Location())
+			}
+		})
+	}
+}
+
+func TestEpochKnownValues(t *testing.T) {
+	if got := FromEpochSeconds(0); !got.Equal(time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("0: got %v, want the Unix epoch", got)
+	}
+	at := time.Date(2021, time.June, 8, 9, 40, 0, 0, time.UTC)
+	if got, want := ToEpochSeconds(at), int64(1623145200); got != want {
+		t.Errorf("seconds: got %d, want %d", got, want)
+	}
+	if got, want := ToEpochMillis(at), int64(1623145200000); got != want {
+		t.Errorf("millis: got %d, want %d", got, want)
+	}
+}
@@ -28,6 +28,72 @@ func f2(arg int) (int, error) {
 	return arg + 3, nil
 }
 
+// Sentinels to test with errors.Is. The typed errors below unwrap to them, so
+// callers can check the kind of failure without caring about the details.
+var (
+	ErrNotFound = errors.New("not found")
+	ErrInvalid  = errors.New("invalid")
+)
+
+type NotFoundError struct {
+	Key string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%q not found", e.Key)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s : %s", e.Field, e.Reason)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalid
+}
+
+var ages = map[string]int{"gopher": 12, "baby": -1}
+
+// userAge wraps the typed errors with %w, adding context while keeping them
+// reachable for errors.Is and errors.As.
+func userAge(name string) (int, error) {
+	age, ok := ages[name]
+	if !ok {
+		return 0, fmt.Errorf("looking up user : %w", &NotFoundError{Key: name})
+	}
+	if age < 0 {
+		return 0, fmt.Errorf("loading user %q : %w", name, &ValidationError{Field: "age", Reason: "must not be negative"})
+	}
+	return age, nil
+}
+
+// describe classifies err the way a caller, e.g. an HTTP handler picking a
+// status code, would.
+func describe(err error) string {
+	var notFound *NotFoundError
+	var invalid *ValidationError
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.As(err, &notFound):
+		return "missing key " + notFound.Key
+	case errors.As(err, &invalid):
+		return "bad field " + invalid.Field
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrInvalid):
+		return "client error"
+	default:
+		return "internal error"
+	}
+}
+
 func main() {
 
 	for _, i := range []int{7, 42} {
@@ -51,6 +117,15 @@ func main() {
 		fmt.Println(ae.arg)
 		fmt.Println(ae.prob)
 	}
+
+	for _, name := range []string{"gopher", "nobody", "baby"} {
+		age, err := userAge(name)
+		fmt.Println(name, age, err)
+		fmt.Println("  is ErrNotFound:", errors.Is(err, ErrNotFound), "is ErrInvalid:", errors.Is(err, ErrInvalid))
+		fmt.Println("  describe:", describe(err))
+	}
+	fmt.Println(describe(fmt.Errorf("wrapped sentinel : %w", ErrNotFound)))
+	fmt.Println(describe(errors.New("disk on fire")))
 }
 
 /*
@@ -61,4 +136,15 @@ func main() {
 	f2 failed: 42 - can't work with it
 	42
 	can't work with it
+	gopher 12 <nil>
+	  is ErrNotFound: false is ErrInvalid: false
+	  describe: ok
+	nobody 0 looking up user : "nobody" not found
+	  is ErrNotFound: true is ErrInvalid: false
+	  describe: missing key nobody
+	baby 0 loading user "baby" : invalid age : must not be negative
+	  is ErrNotFound: false is ErrInvalid: true
+	  describe: bad field age
+	client error
+	internal error
 */
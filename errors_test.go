@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUserAgeWrapsTypedErrors(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		if age, err := userAge("gopher"); err != nil || age != 12 {
+			t.Errorf("got %d, %v, want 12, nil", age, err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := userAge("nobody")
+		if !strings.HasPrefix(err.Error(), "looking up user : ") {
+			t.Errorf("got %q, want the context added with %%w", err)
+		}
+		if !errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalid) {
+			t.Errorf("got %v, want it to be ErrNotFound only", err)
+		}
+		var notFound *NotFoundError
+		if !errors.As(err, &notFound) || notFound.Key != "nobody" {
+			t.Errorf("got %#v, want a *NotFoundError for nobody", notFound)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := userAge("baby")
+		if !errors.Is(err, ErrInvalid) || errors.Is(err, ErrNotFound) {
+			t.Errorf("got %v, want it to be ErrInvalid only", err)
+		}
+		var invalid *ValidationError
+		if !errors.As(err, &invalid) || invalid.Field != "age" {
+			t.Errorf("got %#v, want a *ValidationError for age", invalid)
+		}
+		var notFound *NotFoundError
+		if errors.As(err, &notFound) {
+			t.Errorf("got %#v, want no *NotFoundError", notFound)
+		}
+	})
+}
+
+func TestDescribe(t *testing.T) {
+	var tests = []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, "ok"},
+		{"not found", &NotFoundError{Key: "k"}, "missing key k"},
+		{"wrapped twice", fmt.Errorf("handler : %w", fmt.Errorf("store : %w", &NotFoundError{Key: "k"})), "missing key k"},
+		{"invalid", fmt.Errorf("loading : %w", &ValidationError{Field: "age"}), "bad field age"},
+		{"bare sentinel", fmt.Errorf("cache : %w", ErrNotFound), "client error"},
+		{"wrapped with %v", fmt.Errorf("cache : %v", ErrNotFound), "internal error"},
+		{"unrelated", errors.New("disk full"), "internal error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describe(tt.err); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgErrorAs(t *testing.T) {
+	_, err := f2(42)
+	var ae *argError
+	if !errors.As(err, &ae) || ae.arg != 42 {
+		t.Errorf("got %v, want an *argError for 42", err)
+	}
+	if _, err := f1(42); errors.As(err, &ae) {
+		t.Errorf("got %v as an *argError, want a plain error", err)
+	}
+}
@@ -2,16 +2,13 @@ package main
 
 import (
 	"os"
-	"os/exec"
-	"syscall"
 )
 
-func main() {
+// ExecReplace lives in exec_process_unix.go and exec_process_windows.go, so
+// run this example with: go run exec_process.go exec_process_unix.go
+// (or exec_process_windows.go on Windows).
 
-	binary, lookErr := exec.LookPath("ls")
-	if lookErr != nil {
-		panic(lookErr)
-	}
+func main() {
 
 	args := []string{"ls", "-a", "-l", "-h"}
 
@@ -19,14 +16,14 @@ func main() {
 
 	//log.Printf("Environment : %s\n", env)
 
-	execErr := syscall.Exec(binary, args, env)
+	execErr := ExecReplace("ls", args, env)
 	if execErr != nil {
 		panic(execErr)
 	}
 }
 
 /*
-	raja@raja-Latitude-3460:~/Documents/coding/golang/go-by-examples$ go run exec_process.go
+	raja@raja-Latitude-3460:~/Documents/coding/golang/go-by-examples$ go run exec_process.go exec_process_unix.go
 	total 76K
 	drwxrwxr-x 3 raja raja 4.0K Jun  7 10:53 .
 	drwxrwxr-x 7 raja raja 4.0K Jun  6 10:05 ..
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package main
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// ExecReplace replaces the current process with name. args must start with
+// the program name, just like argv. It only returns if the exec failed.
+func ExecReplace(name string, args, env []string) error {
+	binary, lookErr := exec.LookPath(name)
+	if lookErr != nil {
+		return lookErr
+	}
+
+	return syscall.Exec(binary, args, env)
+}
@@ -0,0 +1,50 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// ExecReplace can't replace the process on Windows, as there is no
+// syscall.Exec. Instead it runs name to completion with our stdio and then
+// exits with its exit code, which looks the same to whoever started us. It
+// only returns if the command could not be run.
+func ExecReplace(name string, args, env []string) error {
+	binary, lookErr := exec.LookPath(name)
+	if lookErr != nil {
+		return lookErr
+	}
+
+	code, err := runReplacement(binary, args, env, os.Stdin, os.Stdout, os.Stderr)
+	if err != nil {
+		return err
+	}
+	os.Exit(code)
+	return nil
+}
+
+// runReplacement runs binary with the given stdio and returns its exit code,
+// leaving the exit itself to ExecReplace so tests can call it.
+func runReplacement(binary string, args, env []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	cmd := exec.Command(binary)
+	cmd.Args = args
+	cmd.Env = env
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	return 0, nil
+}
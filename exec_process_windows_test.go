@@ -0,0 +1,44 @@
+//go:build windows
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRunReplacement(t *testing.T) {
+	binary, err := exec.LookPath("cmd")
+	if err != nil {
+		t.Skipf("cmd not found : %v", err)
+	}
+
+	var tests = []struct {
+		name     string
+		args     []string
+		wantOut  string
+		wantCode int
+	}{
+		{"output", []string{"cmd", "/c", "echo hello"}, "hello", 0},
+		{"exit code", []string{"cmd", "/c", "exit 3"}, "", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code, err := runReplacement(binary, tt.args, os.Environ(), nil, &stdout, &stderr)
+			if err != nil {
+				t.Fatalf("runReplacement failed : %v", err)
+			}
+			if got := strings.TrimSpace(stdout.String()); got != tt.wantOut {
+				t.Errorf("got output %q, want %q", got, tt.wantOut)
+			}
+			if code != tt.wantCode {
+				t.Errorf("got exit code %d, want %d", code, tt.wantCode)
+			}
+		})
+	}
+}
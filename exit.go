@@ -3,20 +3,57 @@ package main
 import (
 	"fmt"
 	"os"
+	"sync"
 )
 
+var (
+	exitMu    sync.Mutex
+	exitHooks []func()
+
+	// osExit is swapped out by tests so Exit can be exercised without
+	// ending the test binary.
+	osExit = os.Exit
+)
+
+// AtExit registers fn to run when the program leaves through Exit.
+func AtExit(fn func()) {
+	exitMu.Lock()
+	defer exitMu.Unlock()
+	exitHooks = append(exitHooks, fn)
+}
+
+// Exit runs the AtExit hooks, newest first like defers, then calls os.Exit.
+func Exit(code int) {
+	exitMu.Lock()
+	hooks := exitHooks
+	exitHooks = nil
+	exitMu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
+	}
+	osExit(code)
+}
+
 func main() {
 
 	defer fmt.Println("defer !")
 
-	os.Exit(3)
+	AtExit(func() { fmt.Println("at exit : first registered") })
+	AtExit(func() { fmt.Println("at exit : second registered") })
+
+	Exit(3)
 }
 
 /*
 	raja@raja-Latitude-3460:~/Documents/coding/golang/go-by-examples$ go run exit.go
+	at exit : second registered
+	at exit : first registered
 	exit status 3
 	raja@raja-Latitude-3460:~/Documents/coding/golang/go-by-examples$ go build exit.go
 	raja@raja-Latitude-3460:~/Documents/coding/golang/go-by-examples$ ./exit
+	at exit : second registered
+	at exit : first registered
 	raja@raja-Latitude-3460:~/Documents/coding/golang/go-by-examples$ echo $?
 	3
 */
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExitRunsHooksNewestFirst(t *testing.T) {
+	defer func(orig func(int)) { osExit = orig }(osExit)
+	var codes []int
+	osExit = func(code int) { codes = append(codes, code) }
+
+	var order []string
+	AtExit(func() { order = append(order, "first") })
+	AtExit(func() { order = append(order, "second") })
+
+	Exit(3)
+
+	if want := []string{"second", "first"}; !slices.Equal(order, want) {
+		t.Errorf("hooks ran in order %v, want %v", order, want)
+	}
+	if !slices.Equal(codes, []int{3}) {
+		t.Errorf("os.Exit called with %v, want [3]", codes)
+	}
+
+	// the hooks were used up by the first Exit
+	Exit(0)
+	if len(order) != 2 {
+		t.Errorf("hooks ran again : %v", order)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// FakeClock is a Clock, as declared in tickers.go and humanize.go, that only
+// moves when Advance is called. It lives in a _test file of its own so the
+// tests of both examples can share it.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []fakeTimer
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.timers = append(c.timers, fakeTimer{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward by d and fires every After that is due.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.ch <- c.now
+	}
+	c.timers = pending
+}
+
+// BlockUntil waits until n Afters are pending, so a test knows the code under
+// test is waiting on the clock before it calls Advance.
+func (c *FakeClock) BlockUntil(n int) {
+	for {
+		c.mu.Lock()
+		pending := len(c.timers)
+		c.mu.Unlock()
+		if pending >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2021, time.June, 8, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	fired := func(ch <-chan time.Time) bool {
+		select {
+		case <-ch:
+			return true
+		default:
+			return false
+		}
+	}
+
+	if !fired(clock.After(0)) {
+		t.Error("After(0): got nothing, want it to fire at once")
+	}
+
+	minute, hour := clock.After(time.Minute), clock.After(time.Hour)
+	clock.BlockUntil(2)
+
+	clock.Advance(59 * time.Second)
+	if fired(minute) || fired(hour) {
+		t.Fatal("after 59s: got a timer firing early")
+	}
+	clock.Advance(time.Second)
+	if !fired(minute) || fired(hour) {
+		t.Fatal("after 1m: want only the minute timer to fire")
+	}
+	clock.Advance(2 * time.Hour)
+	if !fired(hour) {
+		t.Error("after 2h1m: got nothing from the hour timer")
+	}
+
+	if got, want := clock.Now(), start.Add(2*time.Hour+time.Minute); !got.Equal(want) {
+		t.Errorf("Now: got %v, want %v", got, want)
+	}
+}
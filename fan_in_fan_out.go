@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Merge forwards the values of all chans to a single channel (fan-in), which
+// is closed once every input is closed. The goroutines exit when the inputs
+// are closed and drained, so the caller has to keep reading the output.
+func Merge[T any](chans ...<-chan T) <-chan T {
+	out := make(chan T)
+	var wg sync.WaitGroup
+	for _, ch := range chans {
+		wg.Add(1)
+		go func(ch <-chan T) {
+			defer wg.Done()
+			for v := range ch {
+				out <- v
+			}
+		}(ch)
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Split deals the values of in round-robin across n channels (fan-out), which
+// are all closed once in is closed. Every output has to be read, since a
+// stalled reader holds up the others. Fewer than one output is treated as one.
+func Split[T any](in <-chan T, n int) []<-chan T {
+	n = max(1, n)
+	outs := make([]chan T, n)
+	result := make([]<-chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T)
+		result[i] = outs[i]
+	}
+
+	go func() {
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+		i := 0
+		for v := range in {
+			outs[i] <- v
+			i = (i + 1) % n
+		}
+	}()
+	return result
+}
+
+// generate returns a channel yielding nums, closed after the last one.
+func generate(nums ...int) <-chan int {
+	out := make(chan int)
+	go func() {
+		defer close(out)
+		for _, n := range nums {
+			out <- n
+		}
+	}()
+	return out
+}
+
+func main() {
+
+	var merged []int
+	for v := range Merge(generate(1, 2, 3), generate(10, 20), generate(100)) {
+		merged = append(merged, v)
+	}
+	sort.Ints(merged) // arrival order depends on scheduling
+	fmt.Println("merged:", merged)
+
+	workers := Split(generate(1, 2, 3, 4, 5, 6, 7), 3)
+	var wg sync.WaitGroup
+	counts := make([][]int, len(workers))
+	for i, ch := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for v := range ch {
+				counts[i] = append(counts[i], v)
+			}
+		}()
+	}
+	wg.Wait()
+	for i, got := range counts {
+		fmt.Println("worker", i, "got", got)
+	}
+}
+
+/*
+	$ go run fan_in_fan_out.go
+	merged: [1 2 3 10 20 100]
+	worker 0 got [1 4 7]
+	worker 1 got [2 5]
+	worker 2 got [3 6]
+*/
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	AssertNoLeaks(t)
+
+	var got []int
+	for v := range Merge(generate(1, 2, 3), generate(10, 20), generate(100)) {
+		got = append(got, v)
+	}
+	slices.Sort(got)
+	if want := []int{1, 2, 3, 10, 20, 100}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if _, ok := <-Merge[int](); ok {
+		t.Error("Merge of no channels yielded a value")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	AssertNoLeaks(t)
+
+	outs := Split(generate(1, 2, 3, 4, 5, 6, 7), 3)
+	got := make([][]int, len(outs))
+	var wg sync.WaitGroup
+	for i, out := range outs {
+		wg.Add(1)
+		go func(i int, out <-chan int) {
+			defer wg.Done()
+			for v := range out {
+				got[i] = append(got[i], v)
+			}
+		}(i, out)
+	}
+	wg.Wait()
+
+	want := [][]int{{1, 4, 7}, {2, 5}, {3, 6}}
+	for i := range want {
+		if !slices.Equal(got[i], want[i]) {
+			t.Errorf("output %d got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
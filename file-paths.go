@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SplitExt splits p into everything before the extension and the extension
+// itself, so base+ext == p. A dotfile like ".gitignore" has no extension.
+func SplitExt(p string) (base, ext string) {
+	name := filepath.Base(p)
+	ext = filepath.Ext(name)
+	if ext == name {
+		ext = ""
+	}
+	return strings.TrimSuffix(p, ext), ext
+}
+
+// EnsureDir creates p and any missing parents. It fails if p exists but is
+// not a directory.
+func EnsureDir(p string) error {
+	if err := os.MkdirAll(p, 0755); err != nil {
+		return fmt.Errorf("ensuring directory %s : %w", p, err)
+	}
+	return nil
+}
+
+// RelativeTo returns target as a path relative to base, e.g. "../c" for base
+// "a/b" and target "a/c".
+func RelativeTo(base, target string) (string, error) {
+	return filepath.Rel(base, target)
+}
+
+func main() {
+
+	p := filepath.Join("dir1", "dir2", "filename")
+	fmt.Println("p:", p)
+
+	fmt.Println(filepath.Join("dir1//", "filename"))
+	fmt.Println(filepath.Join("dir1/../dir1", "filename"))
+
+	fmt.Println("Dir(p):", filepath.Dir(p))
+	fmt.Println("Base(p):", filepath.Base(p))
+
+	fmt.Println(filepath.IsAbs("dir/file"))
+	fmt.Println(filepath.IsAbs("/dir/file"))
+
+	fmt.Println()
+	for _, name := range []string{"config.json", "dir/archive.tar.gz", ".gitignore", "dir/.env.local", "Makefile"} {
+		base, ext := SplitExt(name)
+		fmt.Printf("%-18s base=%q ext=%q\n", name, base, ext)
+	}
+
+	dir := filepath.Join(os.TempDir(), "file-paths", "a", "b")
+	if err := EnsureDir(dir); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(filepath.Join(os.TempDir(), "file-paths"))
+	fmt.Println("EnsureDir:", EnsureDir(dir))
+
+	rel, err := RelativeTo("a/b", "a/c/t/file")
+	fmt.Println(rel, err)
+	_, err = RelativeTo("a/b", "/abs/path")
+	fmt.Println(err)
+}
+
+/*
+	$ go run file-paths.go
+	p: dir1/dir2/filename
+	dir1/filename
+	dir1/filename
+	Dir(p): dir1/dir2
+	Base(p): filename
+	false
+	true
+
+	config.json        base="config" ext=".json"
+	dir/archive.tar.gz base="dir/archive.tar" ext=".gz"
+	.gitignore         base=".gitignore" ext=""
+	dir/.env.local     base="dir/.env" ext=".local"
+	Makefile           base="Makefile" ext=""
+	EnsureDir: <nil>
+	../c/t/file <nil>
+	Rel: can't make /abs/path relative to a/b
+*/
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitExt(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantBase string
+		wantExt  string
+	}{
+		{"app.go", "app", ".go"},
+		{"dir/file.tar.gz", "dir/file.tar", ".gz"},
+		{"a.b/c", "a.b/c", ""},
+		{".gitignore", ".gitignore", ""},
+		{"dir/.gitignore", "dir/.gitignore", ""},
+		{"dir/.config.json", "dir/.config", ".json"},
+		{"../rel/name.txt", "../rel/name", ".txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			in := filepath.FromSlash(tt.in)
+			base, ext := SplitExt(in)
+			if base != filepath.FromSlash(tt.wantBase) || ext != tt.wantExt {
+				t.Errorf("got %q, %q, want %q, %q", base, ext, tt.wantBase, tt.wantExt)
+			}
+			if base+ext != in {
+				t.Errorf("got %q, want base+ext == %q", base+ext, in)
+			}
+		})
+	}
+}
+
+func TestEnsureDir(t *testing.T) {
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "a", "b", "c")
+
+	for i := 0; i < 2; i++ {
+		if err := EnsureDir(nested); err != nil {
+			t.Fatalf("call %d: %v", i+1, err)
+		}
+	}
+	if info, err := os.Stat(nested); err != nil || !info.IsDir() {
+		t.Errorf("got %v, want %s to be a directory", err, nested)
+	}
+
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := EnsureDir(file); err == nil {
+		t.Error("existing file: got nil error")
+	}
+}
+
+func TestRelativeTo(t *testing.T) {
+	tests := []struct {
+		base, target string
+		want         string
+		wantErr      bool
+	}{
+		{"a/b", "a/c", "../c", false},
+		{"a", "a/b/c", "b/c", false},
+		{"a/b", "a/b", ".", false},
+		{"/abs", "rel", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.base+" to "+tt.target, func(t *testing.T) {
+			got, err := RelativeTo(filepath.FromSlash(tt.base), filepath.FromSlash(tt.target))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got %v, want error %v", err, tt.wantErr)
+			}
+			if got != filepath.FromSlash(tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func Map[T, U any](vs []T, f func(T) U) []U {
+	vsm := make([]U, len(vs))
+	for i, v := range vs {
+		vsm[i] = f(v)
+	}
+	return vsm
+}
+
+func Filter[T any](vs []T, f func(T) bool) []T {
+	vsf := make([]T, 0)
+	for _, v := range vs {
+		if f(v) {
+			vsf = append(vsf, v)
+		}
+	}
+	return vsf
+}
+
+func Reduce[T, U any](vs []T, initial U, f func(U, T) U) U {
+	acc := initial
+	for _, v := range vs {
+		acc = f(acc, v)
+	}
+	return acc
+}
+
+func main() {
+
+	nums := []int{1, 2, 3, 4, 5, 6}
+
+	fmt.Printf("%q\n", Map(nums, strconv.Itoa))
+
+	fmt.Println(Filter(nums, func(n int) bool {
+		return n%2 == 0
+	}))
+
+	fmt.Println(Reduce(nums, 0, func(sum, n int) int {
+		return sum + n
+	}))
+
+	var none []int
+	fmt.Println(len(Map(none, strconv.Itoa)), len(Filter(none, func(int) bool { return true })), Reduce(none, 10, func(a, b int) int { return a + b }))
+}
+
+/*
+	$ go run generics.go
+	["1" "2" "3" "4" "5" "6"]
+	[2 4 6]
+	21
+	0 0 10
+*/
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestMapFilterReduce(t *testing.T) {
+	nums := []int{1, 2, 3, 4, 5, 6}
+
+	if got, want := Map(nums, strconv.Itoa), []string{"1", "2", "3", "4", "5", "6"}; !slices.Equal(got, want) {
+		t.Errorf("Map got %q, want %q", got, want)
+	}
+	if got, want := Filter(nums, func(n int) bool { return n%2 == 0 }), []int{2, 4, 6}; !slices.Equal(got, want) {
+		t.Errorf("Filter got %v, want %v", got, want)
+	}
+	if got := Reduce(nums, 0, func(sum, n int) int { return sum + n }); got != 21 {
+		t.Errorf("Reduce got %d, want 21", got)
+	}
+
+	var none []int
+	if got := Map(none, strconv.Itoa); got == nil || len(got) != 0 {
+		t.Errorf("Map of nil got %#v, want an empty slice", got)
+	}
+	if got := Filter(none, func(int) bool { return true }); got == nil || len(got) != 0 {
+		t.Errorf("Filter of nil got %#v, want an empty slice", got)
+	}
+	if got := Reduce(none, 10, func(a, b int) int { return a + b }); got != 10 {
+		t.Errorf("Reduce of nil got %d, want the initial 10", got)
+	}
+}
@@ -2,20 +2,767 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"math"
+	"math/rand"
 	"net/http"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
 )
 
-func main() {
-	resp, err := http.Get("https://gobyexample.com/")
+type FetchOptions struct {
+	Timeout      time.Duration
+	MaxRetries   int
+	RetryBackoff time.Duration
+	// Stats, when set, records the duration of every request Fetch makes.
+	Stats *Stats
+	// MaxBodyBytes caps how much of a body Fetch reads, 0 means no limit.
+	MaxBodyBytes int64
+	// Transport tunes connection pooling and keep-alives. Share one between
+	// calls so they reuse connections; nil uses http.DefaultTransport. Use
+	// WithProxy to go through an HTTP proxy.
+	Transport *http.Transport
+	// MaxRetryAfter caps how long a Retry-After header can make Fetch wait,
+	// 0 means no cap.
+	MaxRetryAfter time.Duration
+
+	// now and after stand in for time.Now and time.After when set, so tests
+	// don't have to really wait.
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+var ErrBodyTooLarge = errors.New("response body too large")
+
+const statsSampleSize = 1024
+
+// Stats collects request latencies. Count and Mean cover every request,
+// while percentiles come from a fixed-size random sample (reservoir
+// sampling), so memory stays bounded however many requests are recorded.
+type Stats struct {
+	mu      sync.Mutex
+	count   int64
+	total   time.Duration
+	samples []time.Duration
+}
+
+func (s *Stats) Record(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.count++
+	s.total += d
+	if len(s.samples) < statsSampleSize {
+		s.samples = append(s.samples, d)
+		return
+	}
+	if i := rand.Int63n(s.count); i < statsSampleSize {
+		s.samples[i] = d
+	}
+}
+
+func (s *Stats) Count() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+func (s *Stats) Mean() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.count == 0 {
+		return 0
+	}
+	return s.total / time.Duration(s.count)
+}
+
+func (s *Stats) P95() time.Duration {
+	s.mu.Lock()
+	sorted := append([]time.Duration(nil), s.samples...)
+	s.mu.Unlock()
+
+	if len(sorted) == 0 {
+		return 0
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[int(math.Ceil(0.95*float64(len(sorted))))-1]
+}
+
+var defaultFetchOptions = FetchOptions{Timeout: 10 * time.Second, MaxRetries: 3, RetryBackoff: 500 * time.Millisecond, MaxRetryAfter: 30 * time.Second}
+
+type Result struct {
+	StatusCode int
+	BodyLength int
+	Err        error
+}
+
+// Fetch GETs the url and returns its body and status code. Network errors,
+// 429 and 5xx responses are retried up to opts.MaxRetries times, doubling the
+// backoff between attempts, and the wait is abandoned as soon as ctx is done.
+// A 429 or 503 with a Retry-After header waits as long as the server asks
+// instead, up to opts.MaxRetryAfter.
+func Fetch(ctx context.Context, url string, opts FetchOptions) ([]byte, int, error) {
+	client := &http.Client{Timeout: opts.Timeout}
+	if opts.Transport != nil {
+		client.Transport = opts.Transport
+	}
+	now, after := opts.now, opts.after
+	if now == nil {
+		now = time.Now
+	}
+	if after == nil {
+		after = time.After
+	}
+	backoff := opts.RetryBackoff
+
+	var lastErr error
+	var lastStatus int
+	var wait time.Duration
+
+	for attempt := 0; attempt <= opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, lastStatus, ctx.Err()
+			case <-after(wait):
+			}
+		}
+
+		start := time.Now()
+		body, status, header, err := fetchOnce(ctx, client, url, opts.MaxBodyBytes)
+		if opts.Stats != nil {
+			opts.Stats.Record(time.Since(start))
+		}
+		if err == nil && status < http.StatusInternalServerError && status != http.StatusTooManyRequests {
+			return body, status, nil
+		}
+		if ctx.Err() != nil {
+			return nil, status, ctx.Err()
+		}
+		if errors.Is(err, ErrBodyTooLarge) {
+			// asking again won't make the body any smaller
+			return nil, status, err
+		}
+		if err == nil {
+			err = fmt.Errorf("server responded with status %d", status)
+		}
+
+		wait = backoff
+		backoff *= 2
+		if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+			if delay, ok := parseRetryAfter(header.Get("Retry-After"), now()); ok {
+				if opts.MaxRetryAfter > 0 && delay > opts.MaxRetryAfter {
+					delay = opts.MaxRetryAfter
+				}
+				wait = delay
+			}
+		}
+		lastErr, lastStatus = err, status
+		log.Printf("attempt %d for %s failed : %v \n", attempt+1, url, err)
+	}
 
+	return nil, lastStatus, fmt.Errorf("giving up on %s after %d attempts : %w", url, opts.MaxRetries+1, lastErr)
+}
+
+// parseRetryAfter reads a Retry-After value, given either as seconds ("120")
+// or as an HTTP date, and returns how long to wait from now.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	at, err := http.ParseTime(value)
 	if err != nil {
-		log.Panicf("Something went wrong while fetching response : %v \n", err)
+		return 0, false
+	}
+	// a date in the past means we may retry right away
+	return max(at.Sub(now), 0), true
+}
+
+// WithProxy returns a copy of o whose requests go through the HTTP proxy at
+// proxyURL, e.g. "http://proxy.local:3128". The proxied clone of o.Transport
+// is made here, once, so every Fetch with the returned options shares its
+// connections, and o.Transport itself is never changed.
+func (o FetchOptions) WithProxy(proxyURL string) (FetchOptions, error) {
+	proxy, err := neturl.Parse(proxyURL)
+	if err != nil {
+		return o, fmt.Errorf("invalid proxy url %q : %w", proxyURL, err)
+	}
+
+	base := o.Transport
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	o.Transport = base.Clone()
+	o.Transport.Proxy = http.ProxyURL(proxy)
+	return o, nil
+}
+
+func fetchOnce(ctx context.Context, client *http.Client, url string, maxBody int64) ([]byte, int, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, nil, err
 	}
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
 
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, nil, err
+	}
 	defer resp.Body.Close()
 
+	reader, err := decodeBody(resp)
+	if err != nil {
+		return nil, resp.StatusCode, resp.Header, err
+	}
+	defer reader.Close()
+
+	if maxBody > 0 {
+		// one byte more than allowed tells us the body didn't fit
+		reader = io.NopCloser(io.LimitReader(reader, maxBody+1))
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, resp.StatusCode, resp.Header, err
+	}
+	if maxBody > 0 && int64(len(body)) > maxBody {
+		return nil, resp.StatusCode, resp.Header, fmt.Errorf("%s is over %d bytes : %w", url, maxBody, ErrBodyTooLarge)
+	}
+	return body, resp.StatusCode, resp.Header, nil
+}
+
+// decodeBody wraps resp.Body according to its Content-Encoding. Since we ask
+// for compression ourselves, the transport leaves the body untouched for us.
+func decodeBody(resp *http.Response) (io.ReadCloser, error) {
+	switch encoding := resp.Header.Get("Content-Encoding"); encoding {
+	case "", "identity":
+		return io.NopCloser(resp.Body), nil
+	case "gzip":
+		return gzip.NewReader(resp.Body)
+	case "deflate":
+		return flate.NewReader(resp.Body), nil
+	default:
+		return nil, fmt.Errorf("unsupported Content-Encoding %q", encoding)
+	}
+}
+
+// FetchAll fetches every url using at most concurrency workers, so no more
+// than that many connections are open at once. A failing url only records its
+// error in its own Result.
+func FetchAll(urls []string, concurrency int) map[string]Result {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobs := make(chan string)
+	results := make(map[string]Result, len(urls))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for w := 1; w <= concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for url := range jobs {
+				body, status, err := Fetch(context.Background(), url, defaultFetchOptions)
+				mu.Lock()
+				results[url] = Result{StatusCode: status, BodyLength: len(body), Err: err}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, url := range urls {
+		jobs <- url
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// Client keeps one *http.Client with its own transport, so connections are
+// kept alive and reused between calls until Close.
+type Client struct {
+	client *http.Client
+}
+
+func NewClient(timeout time.Duration) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	return &Client{client: &http.Client{Timeout: timeout, Transport: transport}}
+}
+
+func (c *Client) Get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req)
+}
+
+func (c *Client) Post(ctx context.Context, url, contentType string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return c.do(req)
+}
+
+// do reads the whole body before closing it, which is what lets the
+// transport put the connection back into its pool.
+func (c *Client) do(req *http.Request) ([]byte, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Close drops the idle connections kept for reuse. The Client can still be
+// used afterwards, it just has to dial again.
+func (c *Client) Close() {
+	c.client.CloseIdleConnections()
+}
+
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d : %s", e.StatusCode, e.Body)
+}
+
+const maxErrorSnippet = 512
+
+// checkStatus turns a non-2xx response into a *StatusError carrying the start
+// of the body.
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorSnippet))
+	return &StatusError{StatusCode: resp.StatusCode, Body: string(snippet)}
+}
+
+func PostJSON(url string, payload interface{}) (*http.Response, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling payload : %w", err)
+	}
+	return http.Post(url, "application/json", bytes.NewReader(data))
+}
+
+// DecodeJSON unmarshals the body of resp into out and closes it. Non-2xx
+// responses come back as a *StatusError.
+func DecodeJSON(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response : %w", err)
+	}
+	return nil
+}
+
+// maxStreamLine is the longest line StreamLines accepts, well past the
+// scanner's 64 KB default.
+const maxStreamLine = 1 << 20
+
+// StreamLines calls fn for every line of the body at url as it is read. The
+// first error returned by fn stops the stream and is handed back to the caller.
+// Non-2xx responses come back as a *StatusError without calling fn.
+func StreamLines(url string, fn func(line string) error) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return err
+	}
+
 	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), maxStreamLine)
+	for scanner.Scan() {
+		if err := fn(scanner.Text()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+type countingWriter struct {
+	written  int64
+	total    int64
+	progress func(written, total int64)
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.written += int64(len(p))
+	if w.progress != nil {
+		w.progress(w.written, w.total)
+	}
+	return len(p), nil
+}
+
+// Download streams url into dest, reporting progress against Content-Length
+// (-1 when the server doesn't send one). The body goes to a temp file next
+// to dest which is renamed into place only once complete, so a failed
+// download never leaves a partial file at dest.
+func Download(url, dest string, progress func(written, total int64)) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".*.part")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	counter := &countingWriter{total: resp.ContentLength, progress: progress}
+	if _, err := io.Copy(io.MultiWriter(tmp, counter), resp.Body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("downloading %s : %w", url, err)
+	}
+	// CreateTemp makes the file 0600, give dest the usual mode instead
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
+
+type CacheEntry struct {
+	ETag         string
+	LastModified string
+	Body         []byte
+}
+
+// Cache stores responses per url for CachingClient. MemoryCache is enough for
+// a single process; anything shared or persistent can implement this too.
+type Cache interface {
+	Get(url string) (CacheEntry, bool)
+	Set(url string, entry CacheEntry)
+	Delete(url string)
+}
+
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]CacheEntry
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]CacheEntry)}
+}
+
+func (c *MemoryCache) Get(url string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[url]
+	return entry, ok
+}
+
+func (c *MemoryCache) Set(url string, entry CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[url] = entry
+}
+
+func (c *MemoryCache) Delete(url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, url)
+}
+
+// CachingClient makes conditional GETs with the ETag / Last-Modified of the
+// previous response, and serves the cached body when the server answers 304.
+type CachingClient struct {
+	Client *http.Client
+	Cache  Cache
+}
+
+func NewCachingClient(cache Cache) *CachingClient {
+	return &CachingClient{Client: http.DefaultClient, Cache: cache}
+}
+
+func (c *CachingClient) Get(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, hit := c.Cache.Get(url)
+	if hit {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && hit {
+		return cached.Body, nil
+	}
+	if err := checkStatus(resp); err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := CacheEntry{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified"), Body: body}
+	if entry.ETag != "" || entry.LastModified != "" {
+		c.Cache.Set(url, entry)
+	} else {
+		// the old validators belong to a body we no longer have
+		c.Cache.Delete(url)
+	}
+	return body, nil
+}
+
+type dedupCall struct {
+	done chan struct{}
+	body []byte
+	err  error
+}
+
+// DedupClient collapses concurrent Gets of the same URL into one request.
+// It hand-rolls what golang.org/x/sync/singleflight does because this repo
+// has no go.mod to pull that dependency in. Every caller gets the same body
+// slice, so it must be treated as read-only. The request runs with the
+// context of the first caller.
+type DedupClient struct {
+	Client *Client
+
+	mu    sync.Mutex
+	calls map[string]*dedupCall
+}
+
+func NewDedupClient(client *Client) *DedupClient {
+	return &DedupClient{Client: client, calls: make(map[string]*dedupCall)}
+}
+
+func (d *DedupClient) Get(ctx context.Context, url string) ([]byte, error) {
+	d.mu.Lock()
+	if call, ok := d.calls[url]; ok {
+		d.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.body, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &dedupCall{done: make(chan struct{})}
+	d.calls[url] = call
+	d.mu.Unlock()
+
+	call.body, call.err = d.Client.Get(ctx, url)
+
+	d.mu.Lock()
+	delete(d.calls, url)
+	d.mu.Unlock()
+	close(call.done)
+
+	return call.body, call.err
+}
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+var breakerStateNames = [...]string{"closed", "open", "half-open"}
+
+func (s BreakerState) String() string {
+	if s < BreakerClosed || s > BreakerHalfOpen {
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+	return breakerStateNames[s]
+}
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Breaker stops calling a failing dependency. After Threshold consecutive
+// failures it opens and rejects calls with ErrCircuitOpen. Once Cooldown has
+// passed it lets a single probe through (half-open): success closes it again,
+// failure re-opens it for another Cooldown. A call that finishes after the
+// breaker changed state since letting it through is ignored, so a slow
+// success from before the trip can't close it.
+type Breaker struct {
+	Threshold int
+	Cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+	// generation counts state changes; each call records the one it was
+	// let through in
+	generation uint64
+	now        func() time.Time
+}
+
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{Threshold: threshold, Cooldown: cooldown, now: time.Now}
+}
+
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance()
+	return b.state
+}
+
+// errPanicked is what Do records for a call whose fn panicked.
+var errPanicked = errors.New("circuit breaker call panicked")
+
+// Do runs fn unless the breaker is open, and counts its error as a failure.
+// A panic in fn counts as a failure too before it carries on up the stack,
+// so a panicking probe can't leave the breaker half-open for good.
+func (b *Breaker) Do(fn func() error) error {
+	generation, err := b.allow()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			b.record(generation, errPanicked)
+			panic(r)
+		}
+	}()
+	err = fn()
+	b.record(generation, err)
+	return err
+}
+
+func (b *Breaker) setState(state BreakerState) {
+	b.state = state
+	b.generation++
+	b.probing = false
+}
+
+// advance moves an open breaker to half-open once the cooldown is over.
+func (b *Breaker) advance() {
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.Cooldown {
+		b.setState(BreakerHalfOpen)
+	}
+}
+
+func (b *Breaker) allow() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.advance()
+
+	switch b.state {
+	case BreakerOpen:
+		return 0, ErrCircuitOpen
+	case BreakerHalfOpen:
+		if b.probing {
+			return 0, ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return b.generation, nil
+}
+
+// record counts the result of a call let through in generation. Only a call
+// from the current closed period or the half-open probe can change anything.
+func (b *Breaker) record(generation uint64, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+
+	if err == nil {
+		if b.state == BreakerHalfOpen {
+			b.setState(BreakerClosed)
+		}
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.Threshold {
+		b.setState(BreakerOpen)
+		b.openedAt = b.now()
+	}
+}
+
+// FetchWithBreaker is Fetch guarded by b, e.g. for polling a flaky endpoint.
+func FetchWithBreaker(ctx context.Context, b *Breaker, url string, opts FetchOptions) ([]byte, int, error) {
+	var body []byte
+	var status int
+	err := b.Do(func() error {
+		var err error
+		body, status, err = Fetch(ctx, url, opts)
+		return err
+	})
+	return body, status, err
+}
+
+func main() {
+	body, _, err := Fetch(context.Background(), "https://gobyexample.com/", defaultFetchOptions)
+	if err != nil {
+		log.Panicf("Something went wrong while fetching response : %v \n", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
 
 	for scanner.Scan() {
 		log.Println(scanner.Text())
@@ -24,6 +771,92 @@ func main() {
 	if err := scanner.Err(); err != nil {
 		log.Panicf("Something went wrong while Reading response : %v \n", err)
 	}
+
+	stats := &Stats{}
+	statsOpts := defaultFetchOptions
+	statsOpts.Stats = stats
+	for i := 0; i < 5; i++ {
+		if _, _, err := Fetch(context.Background(), "https://gobyexample.com/", statsOpts); err != nil {
+			log.Panicf("Something went wrong while fetching response : %v \n", err)
+		}
+	}
+	log.Printf("requests : %d, mean : %v, p95 : %v \n", stats.Count(), stats.Mean(), stats.P95())
+
+	urls := []string{"https://gobyexample.com/closures", "https://gobyexample.com/context", "https://gobyexample.com/missing"}
+	for url, result := range FetchAll(urls, 2) {
+		log.Printf("%s => status : %d, length : %d, error : %v \n", url, result.StatusCode, result.BodyLength, result.Err)
+	}
+
+	resp, err := PostJSON("https://httpbin.org/post", map[string]string{"topic": "http-clients"})
+	if err != nil {
+		log.Panicf("Something went wrong while posting json : %v \n", err)
+	}
+
+	var echoed struct {
+		JSON map[string]string `json:"json"`
+	}
+	if err := DecodeJSON(resp, &echoed); err != nil {
+		log.Panicf("Something went wrong while decoding json : %v \n", err)
+	}
+	log.Printf("server echoed : %v \n", echoed.JSON)
+
+	errEnough := errors.New("read enough lines")
+	lines := 0
+	err = StreamLines("https://gobyexample.com/", func(line string) error {
+		lines++
+		if lines > 5 {
+			return errEnough
+		}
+		log.Printf("[%d] %s \n", lines, line)
+		return nil
+	})
+	if err != nil && !errors.Is(err, errEnough) {
+		log.Panicf("Something went wrong while streaming lines : %v \n", err)
+	}
+
+	err = Download("https://gobyexample.com/", "/tmp/gobyexample.html", func(written, total int64) {
+		log.Printf("downloaded %d of %d bytes \n", written, total)
+	})
+	if err != nil {
+		log.Panicf("Something went wrong while downloading : %v \n", err)
+	}
+
+	caching := NewCachingClient(NewMemoryCache())
+	for i := 1; i <= 2; i++ {
+		page, err := caching.Get("https://gobyexample.com/")
+		if err != nil {
+			log.Panicf("Something went wrong while polling : %v \n", err)
+		}
+		log.Printf("poll %d : %d bytes \n", i, len(page))
+	}
+
+	client := NewClient(10 * time.Second)
+	defer client.Close()
+	for _, url := range []string{"https://gobyexample.com/closures", "https://gobyexample.com/context"} {
+		page, err := client.Get(context.Background(), url)
+		if err != nil {
+			log.Panicf("Something went wrong while fetching %s : %v \n", url, err)
+		}
+		log.Printf("%s : %d bytes \n", url, len(page))
+	}
+
+	dedup := NewDedupClient(client)
+	var wg sync.WaitGroup
+	for i := 1; i <= 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			page, err := dedup.Get(context.Background(), "https://gobyexample.com/")
+			log.Printf("dedup caller %d : %d bytes, error : %v \n", i, len(page), err)
+		}(i)
+	}
+	wg.Wait()
+
+	breaker := NewBreaker(3, 30*time.Second)
+	for i := 1; i <= 5; i++ {
+		_, status, err := FetchWithBreaker(context.Background(), breaker, "http://127.0.0.1:1/", FetchOptions{Timeout: 10 * time.Second})
+		log.Printf("attempt %d => status : %d, breaker : %v, error : %v \n", i, status, breaker.State(), err)
+	}
 }
 
 /*
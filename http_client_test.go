@@ -0,0 +1,957 @@
+package main
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingTimes answers 500 to the first n requests and "ok" afterwards.
+func failingTimes(n int32, hits *atomic.Int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) <= n {
+			http.Error(w, "try again", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("ok"))
+	}
+}
+
+func TestFetch(t *testing.T) {
+	var tests = []struct {
+		name     string
+		handler  func(hits *atomic.Int32) http.HandlerFunc
+		opts     FetchOptions
+		wantErr  bool
+		wantBody string
+		wantHits int32
+	}{
+		{
+			name:     "succeeds first time",
+			handler:  func(hits *atomic.Int32) http.HandlerFunc { return failingTimes(0, hits) },
+			opts:     FetchOptions{Timeout: time.Second, MaxRetries: 3, RetryBackoff: time.Millisecond},
+			wantBody: "ok",
+			wantHits: 1,
+		},
+		{
+			name:     "fails twice then succeeds",
+			handler:  func(hits *atomic.Int32) http.HandlerFunc { return failingTimes(2, hits) },
+			opts:     FetchOptions{Timeout: time.Second, MaxRetries: 3, RetryBackoff: time.Millisecond},
+			wantBody: "ok",
+			wantHits: 3,
+		},
+		{
+			name:     "gives up after MaxRetries",
+			handler:  func(hits *atomic.Int32) http.HandlerFunc { return failingTimes(10, hits) },
+			opts:     FetchOptions{Timeout: time.Second, MaxRetries: 2, RetryBackoff: time.Millisecond},
+			wantErr:  true,
+			wantHits: 3,
+		},
+		{
+			name: "times out",
+			handler: func(hits *atomic.Int32) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					hits.Add(1)
+					select {
+					case <-time.After(time.Second):
+					case <-r.Context().Done():
+					}
+				}
+			},
+			opts:     FetchOptions{Timeout: 50 * time.Millisecond, MaxRetries: 1, RetryBackoff: time.Millisecond},
+			wantErr:  true,
+			wantHits: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits atomic.Int32
+			srv := httptest.NewServer(tt.handler(&hits))
+			defer srv.Close()
+
+			body, _, err := Fetch(context.Background(), srv.URL, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fetch error = %v, want error %v", err, tt.wantErr)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("got body %q, want %q", body, tt.wantBody)
+			}
+			if got := hits.Load(); got != tt.wantHits {
+				t.Errorf("got %d requests, want %d", got, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestFetchStopsWaitingOnCancel(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(failingTimes(10, &hits))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err := Fetch(ctx, srv.URL, FetchOptions{Timeout: time.Second, MaxRetries: 3, RetryBackoff: time.Hour})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Fetch took %v to notice the cancelled context", elapsed)
+	}
+}
+
+func TestFetchDecodesBody(t *testing.T) {
+	const want = "hello, compressed world"
+
+	var tests = []struct {
+		encoding string
+		encode   func(w io.Writer) io.WriteCloser
+		wantErr  bool
+	}{
+		{"identity", nil, false},
+		{"gzip", func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }, false},
+		{"deflate", func(w io.Writer) io.WriteCloser {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return fw
+		}, false},
+		{"br", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Encoding", tt.encoding)
+				if tt.encode == nil {
+					io.WriteString(w, want)
+					return
+				}
+				enc := tt.encode(w)
+				io.WriteString(enc, want)
+				enc.Close()
+			}))
+			defer srv.Close()
+
+			body, _, err := Fetch(context.Background(), srv.URL, FetchOptions{Timeout: time.Second, RetryBackoff: time.Millisecond})
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "unsupported Content-Encoding") {
+					t.Fatalf("got error %v, want unsupported Content-Encoding", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch failed : %v", err)
+			}
+			if string(body) != want {
+				t.Errorf("got body %q, want %q", body, want)
+			}
+		})
+	}
+}
+
+func TestFetchAllLimitsConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		io.WriteString(w, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	var urls []string
+	for i := 0; i < 10; i++ {
+		urls = append(urls, fmt.Sprintf("%s/page/%d", srv.URL, i))
+	}
+
+	results := FetchAll(urls, 3)
+	if len(results) != len(urls) {
+		t.Fatalf("got %d results, want %d", len(results), len(urls))
+	}
+	for _, url := range urls {
+		res := results[url]
+		if res.Err != nil || res.StatusCode != http.StatusOK {
+			t.Errorf("%s : got status %d, error %v", url, res.StatusCode, res.Err)
+		}
+		if want := len(strings.TrimPrefix(url, srv.URL)); res.BodyLength != want {
+			t.Errorf("%s : got body length %d, want %d", url, res.BodyLength, want)
+		}
+	}
+	if got := peak.Load(); got > 3 {
+		t.Errorf("saw %d requests in flight, want at most 3", got)
+	}
+}
+
+// countingListener counts the connections it accepts.
+type countingListener struct {
+	net.Listener
+	accepted atomic.Int32
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.accepted.Add(1)
+	}
+	return conn, err
+}
+
+func TestClientReusesConnections(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Write([]byte(strings.Repeat("x", 64*1024)))
+	}))
+	ln := &countingListener{Listener: srv.Listener}
+	srv.Listener = ln
+	srv.Start()
+	defer srv.Close()
+
+	c := NewClient(5 * time.Second)
+	defer c.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		if _, err := c.Get(ctx, srv.URL); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Post(ctx, srv.URL, "text/plain", strings.NewReader("hello")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := ln.accepted.Load(); got != 1 {
+		t.Errorf("got %d connections for 10 sequential calls, want 1", got)
+	}
+
+	// after Close the idle connection is gone, so the next call dials again
+	c.Close()
+	if _, err := c.Get(ctx, srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if got := ln.accepted.Load(); got != 2 {
+		t.Errorf("got %d connections after Close, want 2", got)
+	}
+}
+
+func TestPostJSONDecodeJSON(t *testing.T) {
+	type message struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.Error(w, "no such thing", http.StatusNotFound)
+			return
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("got Content-Type %q, want application/json", ct)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.Copy(w, r.Body)
+	}))
+	defer srv.Close()
+
+	t.Run("echo", func(t *testing.T) {
+		want := message{Name: "gopher", Count: 3}
+		resp, err := PostJSON(srv.URL, want)
+		if err != nil {
+			t.Fatalf("PostJSON failed : %v", err)
+		}
+		var got message
+		if err := DecodeJSON(resp, &got); err != nil {
+			t.Fatalf("DecodeJSON failed : %v", err)
+		}
+		if got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("status error", func(t *testing.T) {
+		resp, err := PostJSON(srv.URL+"/missing", message{})
+		if err != nil {
+			t.Fatalf("PostJSON failed : %v", err)
+		}
+		var statusErr *StatusError
+		if err := DecodeJSON(resp, &message{}); !errors.As(err, &statusErr) {
+			t.Fatalf("got error %v, want a *StatusError", err)
+		}
+		if statusErr.StatusCode != http.StatusNotFound || !strings.Contains(statusErr.Body, "no such thing") {
+			t.Errorf("got %+v, want 404 with the body snippet", statusErr)
+		}
+	})
+}
+
+func TestStreamLines(t *testing.T) {
+	long := strings.Repeat("x", 100*1024)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.Error(w, "gone", http.StatusNotFound)
+		case "/long":
+			fmt.Fprintf(w, "short\n%s\nshort\n", long)
+		default:
+			for i := 1; i <= 5; i++ {
+				fmt.Fprintf(w, "line %d\n", i)
+			}
+		}
+	}))
+	defer srv.Close()
+
+	t.Run("counts lines", func(t *testing.T) {
+		var lines []string
+		err := StreamLines(srv.URL, func(line string) error {
+			lines = append(lines, line)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("StreamLines failed : %v", err)
+		}
+		if len(lines) != 5 || lines[4] != "line 5" {
+			t.Errorf("got %q, want five lines", lines)
+		}
+	})
+
+	t.Run("aborts after line 3", func(t *testing.T) {
+		errStop := errors.New("stop")
+		var seen int
+		err := StreamLines(srv.URL, func(line string) error {
+			seen++
+			if seen == 3 {
+				return errStop
+			}
+			return nil
+		})
+		if !errors.Is(err, errStop) {
+			t.Fatalf("got error %v, want %v", err, errStop)
+		}
+		if seen != 3 {
+			t.Errorf("fn called %d times, want 3", seen)
+		}
+	})
+
+	t.Run("line over 64K", func(t *testing.T) {
+		var lengths []int
+		err := StreamLines(srv.URL+"/long", func(line string) error {
+			lengths = append(lengths, len(line))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("StreamLines failed : %v", err)
+		}
+		if len(lengths) != 3 || lengths[1] != len(long) {
+			t.Errorf("got line lengths %v, want [5 %d 5]", lengths, len(long))
+		}
+	})
+
+	t.Run("status error", func(t *testing.T) {
+		called := false
+		err := StreamLines(srv.URL+"/missing", func(line string) error {
+			called = true
+			return nil
+		})
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+			t.Fatalf("got error %v, want a 404 *StatusError", err)
+		}
+		if called {
+			t.Error("fn was called for an error response")
+		}
+	})
+}
+
+func TestDownload(t *testing.T) {
+	payload := strings.Repeat("0123456789", 10000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/truncated" {
+			// promise more than we send, so the client sees an unexpected EOF
+			w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+			io.WriteString(w, payload[:100])
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+		io.WriteString(w, payload)
+	}))
+	defer srv.Close()
+
+	t.Run("known size", func(t *testing.T) {
+		dest := filepath.Join(t.TempDir(), "file.txt")
+		var lastWritten, lastTotal int64
+		calls := 0
+		err := Download(srv.URL, dest, func(written, total int64) {
+			calls++
+			lastWritten, lastTotal = written, total
+		})
+		if err != nil {
+			t.Fatalf("Download failed : %v", err)
+		}
+
+		got, err := os.ReadFile(dest)
+		if err != nil || string(got) != payload {
+			t.Fatalf("dest holds %d bytes, %v, want the %d byte payload", len(got), err, len(payload))
+		}
+		if calls == 0 || lastWritten != int64(len(payload)) || lastTotal != int64(len(payload)) {
+			t.Errorf("last progress %d/%d after %d calls, want %d/%d", lastWritten, lastTotal, calls, len(payload), len(payload))
+		}
+		info, err := os.Stat(dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if runtime.GOOS != "windows" && info.Mode().Perm() != 0644 {
+			t.Errorf("dest mode %v, want %v", info.Mode().Perm(), os.FileMode(0644))
+		}
+	})
+
+	t.Run("no partial file", func(t *testing.T) {
+		dir := t.TempDir()
+		dest := filepath.Join(dir, "file.txt")
+		if err := Download(srv.URL+"/truncated", dest, nil); err == nil {
+			t.Fatal("Download of a truncated body succeeded")
+		}
+		entries, _ := os.ReadDir(dir)
+		if len(entries) != 0 {
+			t.Errorf("left %d files behind, first %q", len(entries), entries[0].Name())
+		}
+	})
+}
+
+func TestCachingClient(t *testing.T) {
+	var full, notModified atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		io.WriteString(w, "cached body")
+	}))
+	defer srv.Close()
+
+	cache := NewMemoryCache()
+	client := NewCachingClient(cache)
+
+	for i := 0; i < 3; i++ {
+		body, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("Get %d failed : %v", i+1, err)
+		}
+		if string(body) != "cached body" {
+			t.Errorf("Get %d got %q", i+1, body)
+		}
+	}
+	if full.Load() != 1 || notModified.Load() != 2 {
+		t.Errorf("server sent %d full responses and %d 304s, want 1 and 2", full.Load(), notModified.Load())
+	}
+	if entry, ok := cache.Get(srv.URL); !ok || entry.ETag != `"v1"` {
+		t.Errorf("cache holds %+v, %v", entry, ok)
+	}
+}
+
+func TestCachingClientDropsStaleEntry(t *testing.T) {
+	var versioned atomic.Bool
+	versioned.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !versioned.Load() {
+			io.WriteString(w, "new body")
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		io.WriteString(w, "old body")
+	}))
+	defer srv.Close()
+
+	cache := NewMemoryCache()
+	client := NewCachingClient(cache)
+	if _, err := client.Get(srv.URL); err != nil {
+		t.Fatalf("first Get failed : %v", err)
+	}
+
+	// the server stops sending validators, so the old entry must go
+	versioned.Store(false)
+	if body, err := client.Get(srv.URL); err != nil || string(body) != "new body" {
+		t.Fatalf("got %q, %v, want %q", body, err, "new body")
+	}
+	if entry, ok := cache.Get(srv.URL); ok {
+		t.Errorf("cache still holds %+v, want no entry", entry)
+	}
+}
+
+func TestStats(t *testing.T) {
+	var empty Stats
+	if empty.Mean() != 0 || empty.P95() != 0 {
+		t.Errorf("empty Stats got mean %v, p95 %v, want 0", empty.Mean(), empty.P95())
+	}
+
+	// 1ms..100ms: mean 50.5ms, and 95 of the 100 are at most 95ms
+	var s Stats
+	for i := 100; i >= 1; i-- {
+		s.Record(time.Duration(i) * time.Millisecond)
+	}
+	if s.Count() != 100 {
+		t.Errorf("got count %d, want 100", s.Count())
+	}
+	if got, want := s.Mean(), 50500*time.Microsecond; got != want {
+		t.Errorf("got mean %v, want %v", got, want)
+	}
+	if got, want := s.P95(), 95*time.Millisecond; got != want {
+		t.Errorf("got p95 %v, want %v", got, want)
+	}
+
+	// past the sample size the mean still covers every request
+	var big Stats
+	for i := 0; i < 3*statsSampleSize; i++ {
+		big.Record(10 * time.Millisecond)
+	}
+	if big.Count() != 3*statsSampleSize || big.Mean() != 10*time.Millisecond || big.P95() != 10*time.Millisecond {
+		t.Errorf("got count %d, mean %v, p95 %v", big.Count(), big.Mean(), big.P95())
+	}
+}
+
+func TestFetchMaxBodyBytes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, strings.Repeat("x", 100))
+	}))
+	defer srv.Close()
+
+	var tests = []struct {
+		name    string
+		limit   int64
+		wantErr bool
+	}{
+		{"under", 200, false},
+		{"exact", 100, false},
+		{"over", 99, true},
+		{"unlimited", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stats Stats
+			body, _, err := Fetch(context.Background(), srv.URL, FetchOptions{Timeout: time.Second, MaxRetries: 2, RetryBackoff: time.Millisecond, MaxBodyBytes: tt.limit, Stats: &stats})
+			if tt.wantErr {
+				if !errors.Is(err, ErrBodyTooLarge) {
+					t.Fatalf("got error %v, want ErrBodyTooLarge", err)
+				}
+				// a body that is too large isn't retried
+				if stats.Count() != 1 {
+					t.Errorf("made %d attempts, want 1", stats.Count())
+				}
+				return
+			}
+			if err != nil || len(body) != 100 {
+				t.Errorf("got %d bytes, %v, want 100", len(body), err)
+			}
+		})
+	}
+}
+
+func TestFetchThroughProxy(t *testing.T) {
+	var proxied atomic.Int32
+	var seenURL atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// a proxy is sent the absolute url of the target
+		proxied.Add(1)
+		seenURL.Store(r.URL.String())
+		io.WriteString(w, "via proxy")
+	}))
+	defer proxy.Close()
+
+	base := &http.Transport{}
+	opts, err := FetchOptions{Timeout: time.Second, RetryBackoff: time.Millisecond, Transport: base}.WithProxy(proxy.URL)
+	if err != nil {
+		t.Fatalf("WithProxy failed : %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		body, _, err := Fetch(context.Background(), "http://example.invalid/page", opts)
+		if err != nil || string(body) != "via proxy" {
+			t.Fatalf("Fetch %d got %q, %v", i+1, body, err)
+		}
+	}
+	if proxied.Load() != 3 || seenURL.Load() != "http://example.invalid/page" {
+		t.Errorf("proxy saw %d requests, last for %v", proxied.Load(), seenURL.Load())
+	}
+
+	if base.Proxy != nil || opts.Transport == base {
+		t.Error("the caller's transport was changed")
+	}
+
+	if _, err := (FetchOptions{}).WithProxy("http://proxy local:3128"); err == nil {
+		t.Error("WithProxy accepted an invalid url")
+	}
+}
+
+// testBreaker returns a breaker whose clock only moves when the test
+// advances *now.
+func testBreaker(threshold int, cooldown time.Duration) (*Breaker, *time.Time) {
+	now := time.Date(2021, time.June, 8, 11, 40, 0, 0, time.UTC)
+	b := NewBreaker(threshold, cooldown)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestBreaker(t *testing.T) {
+	errDown := errors.New("down")
+	fail := func() error { return errDown }
+	succeed := func() error { return nil }
+
+	t.Run("trips after threshold", func(t *testing.T) {
+		b, _ := testBreaker(3, time.Minute)
+		for i := 0; i < 2; i++ {
+			b.Do(fail)
+		}
+		if got := b.State(); got != BreakerClosed {
+			t.Fatalf("after 2 failures: got %v, want closed", got)
+		}
+		b.Do(fail)
+		if got := b.State(); got != BreakerOpen {
+			t.Fatalf("after 3 failures: got %v, want open", got)
+		}
+
+		calls := 0
+		if err := b.Do(func() error { calls++; return nil }); !errors.Is(err, ErrCircuitOpen) || calls != 0 {
+			t.Errorf("open: got %v after %d calls, want ErrCircuitOpen without calling", err, calls)
+		}
+	})
+
+	t.Run("success resets the count", func(t *testing.T) {
+		b, _ := testBreaker(3, time.Minute)
+		b.Do(fail)
+		b.Do(fail)
+		b.Do(succeed)
+		b.Do(fail)
+		b.Do(fail)
+		if got := b.State(); got != BreakerClosed {
+			t.Errorf("got %v, want closed: the failures weren't consecutive", got)
+		}
+	})
+
+	t.Run("cooldown then half-open probe", func(t *testing.T) {
+		b, now := testBreaker(1, time.Minute)
+		b.Do(fail)
+
+		*now = now.Add(59 * time.Second)
+		if got := b.State(); got != BreakerOpen {
+			t.Fatalf("before cooldown: got %v, want open", got)
+		}
+		*now = now.Add(time.Second)
+		if got := b.State(); got != BreakerHalfOpen {
+			t.Fatalf("after cooldown: got %v, want half-open", got)
+		}
+
+		// only one probe at a time
+		probe := slowCall(b, nil)
+		if err := b.Do(succeed); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("second call while probing: got %v, want ErrCircuitOpen", err)
+		}
+		if err := probe(); err != nil {
+			t.Fatal(err)
+		}
+		if got := b.State(); got != BreakerClosed {
+			t.Errorf("after a good probe: got %v, want closed", got)
+		}
+	})
+
+	t.Run("failed probe re-opens", func(t *testing.T) {
+		b, now := testBreaker(5, time.Minute)
+		for i := 0; i < 5; i++ {
+			b.Do(fail)
+		}
+		*now = now.Add(time.Minute)
+		b.Do(fail)
+		if got := b.State(); got != BreakerOpen {
+			t.Fatalf("after a failed probe: got %v, want open", got)
+		}
+		*now = now.Add(59 * time.Second)
+		if got := b.State(); got != BreakerOpen {
+			t.Errorf("got %v, want open for a whole new cooldown", got)
+		}
+	})
+
+	t.Run("panicking probe re-opens", func(t *testing.T) {
+		b, now := testBreaker(1, time.Minute)
+		b.Do(fail)
+		*now = now.Add(time.Minute)
+
+		func() {
+			defer func() {
+				if r := recover(); r != "probe blew up" {
+					t.Errorf("got panic %v, want the probe's panic passed on", r)
+				}
+			}()
+			b.Do(func() error { panic("probe blew up") })
+		}()
+		if got := b.State(); got != BreakerOpen {
+			t.Fatalf("after a panicking probe: got %v, want open", got)
+		}
+
+		// the next cooldown lets a new probe through
+		*now = now.Add(time.Minute)
+		if err := b.Do(succeed); err != nil || b.State() != BreakerClosed {
+			t.Errorf("next probe got %v, state %v, want nil and closed", err, b.State())
+		}
+	})
+
+	t.Run("stale success does not close", func(t *testing.T) {
+		b, now := testBreaker(2, time.Minute)
+
+		// two slow calls are let through while closed, then it trips
+		first := slowCall(b, nil)
+		second := slowCall(b, nil)
+		b.Do(fail)
+		b.Do(fail)
+
+		first()
+		if got := b.State(); got != BreakerOpen {
+			t.Errorf("stale success while open: got %v, want still open", got)
+		}
+
+		*now = now.Add(time.Minute)
+		second()
+		if got := b.State(); got != BreakerHalfOpen {
+			t.Errorf("stale success while half-open: got %v, want still half-open", got)
+		}
+
+		// the probe itself still decides
+		b.Do(succeed)
+		if got := b.State(); got != BreakerClosed {
+			t.Errorf("after the probe: got %v, want closed", got)
+		}
+	})
+
+	t.Run("stale failure does not count", func(t *testing.T) {
+		b, now := testBreaker(1, time.Minute)
+		slow := slowCall(b, errDown)
+		b.Do(fail)
+		*now = now.Add(time.Minute)
+		b.Do(succeed)
+
+		slow()
+		if got := b.State(); got != BreakerClosed {
+			t.Errorf("got %v, want closed: the failure is from before the trip", got)
+		}
+	})
+}
+
+// slowCall starts b.Do with a call that returns err once the returned
+// function is called, which then waits for Do to return. slowCall itself
+// returns as soon as the call has been let through.
+func slowCall(b *Breaker, err error) func() error {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Do(func() error {
+			close(started)
+			<-release
+			return err
+		})
+	}()
+	<-started
+	return func() error {
+		close(release)
+		return <-done
+	}
+}
+
+func TestDedupClient(t *testing.T) {
+	var hits atomic.Int32
+	arrived := make(chan struct{}, 1)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			arrived <- struct{}{}
+			<-release
+		}
+		w.Write([]byte("shared"))
+	}))
+	defer srv.Close()
+
+	d := NewDedupClient(NewClient(5 * time.Second))
+	defer d.Client.Close()
+
+	const callers = 20
+	bodies := make(chan string, callers)
+	var started sync.WaitGroup
+	var finished sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		started.Add(1)
+		finished.Add(1)
+		go func() {
+			defer finished.Done()
+			started.Done()
+			body, err := d.Get(context.Background(), srv.URL)
+			if err != nil {
+				t.Error(err)
+			}
+			bodies <- string(body)
+		}()
+	}
+
+	// hold the one request until every caller has had time to join it
+	<-arrived
+	started.Wait()
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	finished.Wait()
+	close(bodies)
+
+	for body := range bodies {
+		if body != "shared" {
+			t.Errorf("got body %q, want %q", body, "shared")
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("got %d server hits for %d concurrent Gets, want 1", got, callers)
+	}
+
+	// nothing is cached: once the call is over, the next Get goes out again
+	if _, err := d.Get(context.Background(), srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("got %d server hits after a later Get, want 2", got)
+	}
+}
+
+func TestDedupClientWaiterCancel(t *testing.T) {
+	release := make(chan struct{})
+	arrived := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(arrived)
+		<-release
+		w.Write([]byte("slow"))
+	}))
+	defer srv.Close()
+
+	d := NewDedupClient(NewClient(5 * time.Second))
+	defer d.Client.Close()
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := d.Get(context.Background(), srv.URL)
+		first <- err
+	}()
+	<-arrived
+
+	// a waiter gives up on its own context without cancelling the request
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := d.Get(ctx, srv.URL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("waiter: got %v, want context.DeadlineExceeded", err)
+	}
+
+	close(release)
+	if err := <-first; err != nil {
+		t.Errorf("first caller: got %v, want nil", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2021, time.June, 8, 11, 40, 0, 0, time.UTC)
+	var tests = []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{"0", 0, true},
+		{"-1", 0, false},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{now.Add(-time.Hour).Format(http.TimeFormat), 0, true},
+		{"", 0, false},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("got %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFetchHonoursRetryAfter(t *testing.T) {
+	now := time.Date(2021, time.June, 8, 11, 40, 0, 0, time.UTC)
+
+	var tests = []struct {
+		name       string
+		status     int
+		retryAfter string
+		maxWait    time.Duration
+		want       time.Duration
+	}{
+		{"seconds", http.StatusTooManyRequests, "120", 0, 2 * time.Minute},
+		{"http date", http.StatusServiceUnavailable, now.Add(90 * time.Second).Format(http.TimeFormat), 0, 90 * time.Second},
+		{"capped", http.StatusTooManyRequests, "3600", time.Minute, time.Minute},
+		{"malformed uses the backoff", http.StatusTooManyRequests, "soon", 0, 10 * time.Millisecond},
+		{"ignored on 500", http.StatusInternalServerError, "120", 0, 10 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if hits.Add(1) == 1 {
+					w.Header().Set("Retry-After", tt.retryAfter)
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.Write([]byte("ok"))
+			}))
+			defer srv.Close()
+
+			var waits []time.Duration
+			opts := FetchOptions{
+				Timeout:       time.Second,
+				MaxRetries:    1,
+				RetryBackoff:  10 * time.Millisecond,
+				MaxRetryAfter: tt.maxWait,
+				now:           func() time.Time { return now },
+				after: func(d time.Duration) <-chan time.Time {
+					waits = append(waits, d)
+					ch := make(chan time.Time, 1)
+					ch <- now.Add(d)
+					return ch
+				},
+			}
+
+			body, status, err := Fetch(context.Background(), srv.URL, opts)
+			if err != nil || status != http.StatusOK || string(body) != "ok" {
+				t.Fatalf("got %q, %d, %v, want ok after one retry", body, status, err)
+			}
+			if len(waits) != 1 || waits[0] != tt.want {
+				t.Errorf("got waits %v, want [%v]", waits, tt.want)
+			}
+		})
+	}
+}
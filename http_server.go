@@ -1,20 +1,770 @@
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
+	"math"
+	"math/big"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 )
 
+const shutdownTimeout = 10 * time.Second
+
+// ready reports whether the server should receive traffic; it starts out
+// false until startup work has finished.
+var ready atomic.Bool
+
+func SetReady(r bool) {
+	ready.Store(r)
+}
+
+type serverConfig struct {
+	Addr         string
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	AuthUser     string
+	AuthPass     string
+	StaticDir    string
+	UploadDir    string
+	MaxUpload    int64
+	RateLimit    int
+	RateBurst    int
+	CORSOrigins  string
+	TLSCert      string
+	TLSKey       string
+	DevTLS       bool
+	LogFormat    string
+	DrainGrace   time.Duration
+}
+
+// parseConfig reads the server flags from args using fs, so tests can feed
+// their own flag set instead of os.Args. The address and timeouts default to
+// SERVER_ADDR, READ_TIMEOUT and WRITE_TIMEOUT, the variables LoadConfig in
+// environment-variables.go reads, and a flag still wins over the variable.
+func parseConfig(fs *flag.FlagSet, args []string) (serverConfig, error) {
+	readTimeout, err := envDuration("READ_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return serverConfig{}, err
+	}
+	writeTimeout, err := envDuration("WRITE_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return serverConfig{}, err
+	}
+
+	var cfg serverConfig
+	fs.StringVar(&cfg.Addr, "addr", envOr("SERVER_ADDR", "127.0.0.1:8080"), "address to listen on (env SERVER_ADDR)")
+	fs.DurationVar(&cfg.ReadTimeout, "read-timeout", readTimeout, "maximum duration for reading a request (env READ_TIMEOUT)")
+	fs.DurationVar(&cfg.WriteTimeout, "write-timeout", writeTimeout, "maximum duration for writing a response (env WRITE_TIMEOUT)")
+	fs.StringVar(&cfg.AuthUser, "auth-user", "gopher", "basic auth user for /headers")
+	fs.StringVar(&cfg.AuthPass, "auth-pass", "gopher", "basic auth password for /headers")
+	fs.StringVar(&cfg.StaticDir, "static-dir", "./static", "directory served under /static/")
+	fs.StringVar(&cfg.UploadDir, "upload-dir", os.TempDir(), "directory files posted to /upload are saved in")
+	fs.Int64Var(&cfg.MaxUpload, "max-upload", 10<<20, "maximum size in bytes of an /upload request")
+	fs.IntVar(&cfg.RateLimit, "rate-limit", 10, "requests per second allowed per client IP")
+	fs.IntVar(&cfg.RateBurst, "rate-burst", 20, "burst of requests allowed per client IP")
+	fs.StringVar(&cfg.CORSOrigins, "cors-origins", "*", "comma separated origins allowed to call the server from a browser")
+	fs.StringVar(&cfg.TLSCert, "tls-cert", "", "TLS certificate file, serves HTTPS together with -tls-key")
+	fs.StringVar(&cfg.TLSKey, "tls-key", "", "TLS private key file")
+	fs.BoolVar(&cfg.DevTLS, "dev-tls", false, "serve HTTPS with a generated self-signed certificate")
+	fs.StringVar(&cfg.LogFormat, "log-format", envOr("LOG_FORMAT", "text"), "request log format, text or json (env LOG_FORMAT)")
+	fs.DurationVar(&cfg.DrainGrace, "drain-grace", 5*time.Second, "how long to keep serving with /readyz failing before shutting down")
+
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+	if cfg.RateLimit < 1 || cfg.RateBurst < 1 {
+		return cfg, fmt.Errorf("-rate-limit and -rate-burst must be at least 1, got %d and %d", cfg.RateLimit, cfg.RateBurst)
+	}
+	return cfg, nil
+}
+
+func envOr(key, def string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return def
+}
+
+func envDuration(key string, def time.Duration) (time.Duration, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return def, fmt.Errorf("invalid %s %q : %w", key, v, err)
+	}
+	return d, nil
+}
+
+// logger receives the structured request records written by Logging, and
+// everything else the server has to say.
+var logger = slog.Default()
+
+// newLogger writes to w as JSON when format is "json", and as key=value text
+// otherwise.
+func newLogger(format string, w io.Writer) *slog.Logger {
+	if format == "json" {
+		return slog.New(slog.NewJSONHandler(w, nil))
+	}
+	return slog.New(slog.NewTextHandler(w, nil))
+}
+
+func WithRequestID(l *slog.Logger, id string) *slog.Logger {
+	return l.With("request_id", id)
+}
+
+// requestLogger is logger tagged with the X-Request-ID of req, if it has one.
+func requestLogger(req *http.Request) *slog.Logger {
+	if id := req.Header.Get("X-Request-ID"); id != "" {
+		return WithRequestID(logger, id)
+	}
+	return logger
+}
+
+func newServer(cfg serverConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         cfg.Addr,
+		Handler:      handler,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+	}
+}
+
 func main() {
 
-	http.HandleFunc("/hello", hello)
-	http.HandleFunc("/headers", headers)
+	cfg, err := parseConfig(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Panicf("Something went wrong while parsing flags : %v \n", err)
+	}
+	logger = newLogger(cfg.LogFormat, os.Stderr)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", hello)
+	mux.Handle("/headers", BasicAuth(cfg.AuthUser, cfg.AuthPass, http.HandlerFunc(headers)))
+	mux.HandleFunc("/time", serverTime)
+	mux.HandleFunc("/healthz", healthz)
+	mux.HandleFunc("/readyz", readyz)
+	mux.HandleFunc("/metrics", serveMetrics)
+	mux.Handle("/static/", StaticHandler("/static/", cfg.StaticDir))
+	mux.Handle("/upload", UploadHandler(cfg.UploadDir, cfg.MaxUpload))
+	mux.HandleFunc("/panic", func(resp http.ResponseWriter, req *http.Request) {
+		panic("something bad happened")
+	})
+
+	cors := CORS(CORSConfig{
+		AllowedOrigins: strings.Split(cfg.CORSOrigins, ","),
+		AllowedMethods: []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+	})
 
-	if err := http.ListenAndServe("127.0.0.1:8080", nil); err != nil {
+	server := newServer(cfg, Chain(mux, Logging, Recover, RateLimit(cfg.RateLimit, cfg.RateBurst), cors, Gzip))
+
+	ln, err := net.Listen("tcp", server.Addr)
+	if err != nil {
 		log.Panicf("Something went wrong while starting Http Server : %v \n", err)
 	}
 
+	tlsConfig, err := loadTLSConfig(cfg)
+	if err != nil {
+		log.Panicf("Something went wrong while setting up TLS : %v \n", err)
+	}
+	scheme := "http"
+	if tlsConfig != nil {
+		ln = tls.NewListener(ln, tlsConfig)
+		scheme = "https"
+	}
+
+	logger.Info("server listening", "addr", scheme+"://"+ln.Addr().String())
+	SetReady(true)
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+
+	if err := serve(server, ln, sigs, cfg.DrainGrace); err != nil {
+		log.Panicf("Something went wrong while running Http Server : %v \n", err)
+	}
+}
+
+// loadTLSConfig returns nil when the server should speak plain HTTP.
+func loadTLSConfig(cfg serverConfig) (*tls.Config, error) {
+	var cert tls.Certificate
+	var err error
+
+	switch {
+	case cfg.TLSCert != "" && cfg.TLSKey != "":
+		cert, err = tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+	case cfg.TLSCert != "" || cfg.TLSKey != "":
+		return nil, errors.New("-tls-cert and -tls-key must be given together")
+	case cfg.DevTLS:
+		host, _, splitErr := net.SplitHostPort(cfg.Addr)
+		if splitErr != nil {
+			return nil, splitErr
+		}
+		cert, err = selfSignedCert([]string{host, "localhost"})
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, nil
+}
+
+// selfSignedCert generates a throwaway certificate for hosts, good enough for
+// local development once the client is told to trust it.
+func selfSignedCert(hosts []string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"go-by-examples dev"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if h != "" {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+// serve runs server on ln until a signal arrives on stop. It then drains:
+// /readyz starts failing so load balancers stop sending traffic, the server
+// keeps serving for grace, and finally it shuts down, giving in-flight
+// requests up to shutdownTimeout to finish. A second signal at any point
+// closes all connections immediately.
+func serve(server *http.Server, ln net.Listener, stop <-chan os.Signal, grace time.Duration) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.Serve(ln)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case sig := <-stop:
+		logger.Info("draining, signal again to force", "signal", sig.String(), "grace", grace)
+	}
+	SetReady(false)
+
+	drain := time.NewTimer(grace)
+	defer drain.Stop()
+	select {
+	case <-drain.C:
+	case err := <-errs:
+		return err
+	case sig := <-stop:
+		logger.Warn("received signal while draining, closing server now", "signal", sig.String())
+		return closeServer(server, errs)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- server.Shutdown(ctx)
+	}()
+	select {
+	case err := <-shutdown:
+		if err != nil {
+			return err
+		}
+	case sig := <-stop:
+		logger.Warn("received signal while shutting down, closing server now", "signal", sig.String())
+		return closeServer(server, errs)
+	}
+
+	if err := <-errs; err != http.ErrServerClosed {
+		return err
+	}
+	logger.Info("server shutdown completed")
+	return nil
+}
+
+// closeServer drops all connections without waiting for handlers.
+func closeServer(server *http.Server, errs <-chan error) error {
+	if err := server.Close(); err != nil {
+		return err
+	}
+	if err := <-errs; err != http.ErrServerClosed {
+		return err
+	}
+	logger.Info("server closed")
+	return nil
+}
+
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps h with mws so that the first middleware is the outermost one,
+// i.e. Chain(h, a, b) serves requests as a(b(h)).
+func Chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// statusRecorder remembers the status code written by a handler. Handlers
+// that never call WriteHeader implicitly answer with 200.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func newStatusRecorder(w http.ResponseWriter) *statusRecorder {
+	return &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// requestMetrics are the counters served at /metrics. They are updated by
+// Logging, so every request through the chain is counted.
+type requestMetrics struct {
+	total    atomic.Int64
+	inFlight atomic.Int64
+	// byClass[2] counts 2xx responses, byClass[5] 5xx and so on
+	byClass [6]atomic.Int64
+}
+
+var metrics = &requestMetrics{}
+
+func (m *requestMetrics) observe(status int) {
+	m.total.Add(1)
+	if class := status / 100; class >= 1 && class <= 5 {
+		m.byClass[class].Add(1)
+	}
+}
+
+// serveMetrics writes the counters in a plain "name value" text format. The
+// request for /metrics itself is in flight while it is being answered.
+func serveMetrics(resp http.ResponseWriter, req *http.Request) {
+	resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(resp, "http_requests_total %d\n", metrics.total.Load())
+	fmt.Fprintf(resp, "http_requests_in_flight %d\n", metrics.inFlight.Load())
+	for class := 1; class <= 5; class++ {
+		fmt.Fprintf(resp, "http_responses_total{class=\"%dxx\"} %d\n", class, metrics.byClass[class].Load())
+	}
+	fmt.Fprintf(resp, "http_panics_total %d\n", panics.Total())
+}
+
+func Logging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		metrics.inFlight.Add(1)
+		defer metrics.inFlight.Add(-1)
+
+		recorder := newStatusRecorder(resp)
+		next.ServeHTTP(recorder, req)
+		metrics.observe(recorder.status)
+
+		requestLogger(req).Info("request",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"status", recorder.status,
+			"duration", time.Since(start))
+	})
+}
+
+// panicCounter counts the panics Recover has turned into 500s, overall and
+// per route.
+type panicCounter struct {
+	mu     sync.Mutex
+	total  int64
+	routes map[string]int64
+}
+
+var panics = &panicCounter{routes: make(map[string]int64)}
+
+func (c *panicCounter) record(route string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.total++
+	c.routes[route]++
+}
+
+func (c *panicCounter) Total() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.total
+}
+
+func (c *panicCounter) Route(route string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.routes[route]
+}
+
+// Recover turns a panicking handler into a 500 whose body and X-Request-ID
+// header carry the request ID, generated if the client sent none, so a user
+// report can be matched with the logged stack. A panic
+// with http.ErrAbortHandler is a deliberate abort and is re-raised for the
+// server to handle by dropping the connection.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			if r == http.ErrAbortHandler {
+				panic(r)
+			}
+
+			route := req.Method + " " + req.URL.Path
+			panics.record(route)
+
+			id := req.Header.Get("X-Request-ID")
+			if id == "" {
+				id = newRequestID()
+			}
+			WithRequestID(logger, id).Error("panic while serving request",
+				"route", route,
+				"panic", fmt.Sprint(r),
+				"stack", string(debug.Stack()))
+			resp.Header().Set("X-Request-ID", id)
+			msg := http.StatusText(http.StatusInternalServerError) + " (request id " + id + ")"
+			http.Error(resp, msg, http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(resp, req)
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		log.Panicf("Something went wrong while generating request id : %v \n", err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// gzipResponseWriter decides on the first write whether to compress: a
+// handler that already set a Content-Encoding, or a status that carries no
+// body, is passed through untouched.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz      *gzip.Writer
+	decided bool
+}
+
+func (w *gzipResponseWriter) decide(status int) {
+	if w.decided {
+		return
+	}
+	w.decided = true
+
+	header := w.Header()
+	switch status {
+	case http.StatusNoContent, http.StatusPartialContent, http.StatusNotModified:
+		// no body, or a byte range of the uncompressed one
+		return
+	}
+	if header.Get("Content-Encoding") != "" {
+		return
+	}
+	header.Set("Content-Encoding", "gzip")
+	header.Del("Content-Length")
+	w.gz = gzip.NewWriter(w.ResponseWriter)
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	// informational responses come before the real one, which decides
+	if code >= 100 && code <= 199 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.decide(code)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	w.decide(http.StatusOK)
+	if w.gz == nil {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.gz.Write(b)
+}
+
+// Flush sends what has been compressed so far, so streaming handlers below
+// Gzip still reach the client as they write.
+func (w *gzipResponseWriter) Flush() {
+	w.decide(http.StatusOK)
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *gzipResponseWriter) Close() error {
+	if w.gz == nil {
+		return nil
+	}
+	return w.gz.Close()
+}
+
+// acceptsGzip reports whether the Accept-Encoding lines of header allow
+// gzip, either by name or through "*", with a q-value above 0.
+func acceptsGzip(header http.Header) bool {
+	gzipQ, anyQ := -1.0, -1.0
+	for _, line := range header.Values("Accept-Encoding") {
+		for _, coding := range strings.Split(line, ",") {
+			name, params, _ := strings.Cut(coding, ";")
+			q := 1.0
+			for _, param := range strings.Split(params, ";") {
+				if k, v, ok := strings.Cut(strings.TrimSpace(param), "="); ok && strings.EqualFold(k, "q") {
+					if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+						q = parsed
+					}
+				}
+			}
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "gzip":
+				gzipQ = q
+			case "*":
+				anyQ = q
+			}
+		}
+	}
+	// naming gzip overrides whatever "*" says
+	if gzipQ >= 0 {
+		return gzipQ > 0
+	}
+	return anyQ > 0
+}
+
+func Gzip(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		resp.Header().Add("Vary", "Accept-Encoding")
+		// a HEAD response has no body to compress
+		if req.Method == http.MethodHead || !acceptsGzip(req.Header) {
+			next.ServeHTTP(resp, req)
+			return
+		}
+
+		gw := &gzipResponseWriter{ResponseWriter: resp}
+		defer func() {
+			if err := gw.Close(); err != nil {
+				requestLogger(req).Error("Something went wrong while closing gzip writer", "err", err)
+			}
+		}()
+		next.ServeHTTP(gw, req)
+	})
+}
+
+// bucket is a token bucket: it holds up to burst tokens and refills at rps
+// tokens per second. Every request takes one token.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+const limiterIdleTimeout = 5 * time.Minute
+
+// RateLimit allows each client IP rps requests per second with bursts of up
+// to burst requests, answering 429 with a Retry-After header otherwise. The
+// bucket of an IP that has been idle for limiterIdleTimeout is dropped, so
+// the map only holds recently seen clients. With rps <= 0 the burst is all an
+// IP ever gets, and the 429 carries no Retry-After. It is a hand-rolled
+// token bucket rather than golang.org/x/time/rate because this repo has no
+// go.mod to pull that dependency in.
+func RateLimit(rps int, burst int) Middleware {
+	var mu sync.Mutex
+	buckets := make(map[string]*bucket)
+	lastSweep := time.Now()
+
+	// take reports whether ip may go ahead, or how long it has to wait.
+	take := func(ip string, now time.Time) (bool, time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if now.Sub(lastSweep) > limiterIdleTimeout {
+			for key, b := range buckets {
+				if now.Sub(b.last) > limiterIdleTimeout {
+					delete(buckets, key)
+				}
+			}
+			lastSweep = now
+		}
+
+		b, ok := buckets[ip]
+		if !ok {
+			b = &bucket{tokens: float64(burst), last: now}
+			buckets[ip] = b
+		}
+
+		b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*float64(rps))
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			return true, 0
+		}
+		if rps <= 0 {
+			// the bucket never refills, there is no point in coming back
+			return false, 0
+		}
+		return false, time.Duration((1 - b.tokens) / float64(rps) * float64(time.Second))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			ip, _, err := net.SplitHostPort(req.RemoteAddr)
+			if err != nil {
+				ip = req.RemoteAddr
+			}
+
+			allowed, wait := take(ip, time.Now())
+			if !allowed {
+				if wait > 0 {
+					resp.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				}
+				http.Error(resp, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(resp, req)
+		})
+	}
+}
+
+type CORSConfig struct {
+	// AllowedOrigins holds exact origins like "https://example.com", or "*"
+	// to allow any origin.
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+}
+
+func (c CORSConfig) allowOrigin(origin string) (string, bool) {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			return "*", true
+		}
+		if allowed == origin {
+			return origin, true
+		}
+	}
+	return "", false
+}
+
+// CORS adds Access-Control-Allow-Origin for allowed origins and answers
+// preflight OPTIONS requests itself. Disallowed origins get no CORS headers,
+// so the browser blocks the response. Unless any origin is allowed, every
+// response carries Vary: Origin, so a cache doesn't hand the answer to a
+// request without Origin to a cross-origin one, or the other way around.
+func CORS(cfg CORSConfig) Middleware {
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	anyOrigin := len(cfg.AllowedOrigins) == 1 && cfg.AllowedOrigins[0] == "*"
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+			origin := req.Header.Get("Origin")
+			if origin != "" || !anyOrigin {
+				resp.Header().Add("Vary", "Origin")
+			}
+			if origin == "" {
+				next.ServeHTTP(resp, req)
+				return
+			}
+
+			allowOrigin, ok := cfg.allowOrigin(origin)
+			preflight := req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != ""
+
+			if ok {
+				resp.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+			}
+			if !preflight {
+				next.ServeHTTP(resp, req)
+				return
+			}
+
+			if ok {
+				resp.Header().Set("Access-Control-Allow-Methods", methods)
+				if headers != "" {
+					resp.Header().Set("Access-Control-Allow-Headers", headers)
+				}
+			}
+			resp.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// BasicAuth only lets requests with the given credentials through to next.
+// Both sides are hashed before comparing, so the constant-time comparison
+// always works on equal lengths and doesn't leak the expected length.
+func BasicAuth(user, pass string, next http.Handler) http.Handler {
+	wantUser := sha256.Sum256([]byte(user))
+	wantPass := sha256.Sum256([]byte(pass))
+
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		gotUser, gotPass, ok := req.BasicAuth()
+		userHash := sha256.Sum256([]byte(gotUser))
+		passHash := sha256.Sum256([]byte(gotPass))
+
+		userMatch := subtle.ConstantTimeCompare(userHash[:], wantUser[:])
+		passMatch := subtle.ConstantTimeCompare(passHash[:], wantPass[:])
+
+		if !ok || userMatch&passMatch != 1 {
+			resp.Header().Set("WWW-Authenticate", `Basic realm="restricted", charset="UTF-8"`)
+			http.Error(resp, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(resp, req)
+	})
 }
 
 func hello(resp http.ResponseWriter, req *http.Request) {
@@ -27,6 +777,134 @@ func headers(resp http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// WriteJSON answers with v encoded as JSON. v is encoded into a buffer first,
+// so an encoding failure can still become a 500 before any header is sent.
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		logger.Error("Something went wrong while encoding json response", "err", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		logger.Error("Something went wrong while writing json response", "err", err)
+	}
+}
+
+type timeResponse struct {
+	Unix    int64
+	RFC3339 string
+}
+
+func serverTime(resp http.ResponseWriter, req *http.Request) {
+	now := time.Now()
+	WriteJSON(resp, http.StatusOK, timeResponse{Unix: now.Unix(), RFC3339: now.Format(time.RFC3339)})
+}
+
+func healthz(resp http.ResponseWriter, req *http.Request) {
+	fmt.Fprintf(resp, "ok\n")
+}
+
+func readyz(resp http.ResponseWriter, req *http.Request) {
+	if !ready.Load() {
+		http.Error(resp, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintf(resp, "ready\n")
+}
+
+// noListingFS hides directories that have no index.html, so http.FileServer
+// answers 404 instead of listing their contents.
+type noListingFS struct {
+	fs http.FileSystem
+}
+
+func (n noListingFS) Open(name string) (http.File, error) {
+	f, err := n.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		index, err := n.fs.Open(path.Join(name, "index.html"))
+		if err != nil {
+			f.Close()
+			return nil, os.ErrNotExist
+		}
+		index.Close()
+	}
+	return f, nil
+}
+
+// StaticHandler serves the files in dir under prefix. http.Dir refuses paths
+// escaping dir, so requests like /static/../secret never leave it.
+func StaticHandler(prefix, dir string) http.Handler {
+	return http.StripPrefix(prefix, http.FileServer(noListingFS{http.Dir(dir)}))
+}
+
+type uploadResponse struct {
+	Filename string
+	Size     int64
+}
+
+// UploadHandler saves the "file" field of a multipart POST into dir. Requests
+// larger than maxBytes are cut off by http.MaxBytesReader and get a 413.
+func UploadHandler(dir string, maxBytes int64) http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			resp.Header().Set("Allow", http.MethodPost)
+			http.Error(resp, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		req.Body = http.MaxBytesReader(resp, req.Body, maxBytes)
+		if err := req.ParseMultipartForm(maxBytes); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(resp, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(resp, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer req.MultipartForm.RemoveAll()
+
+		file, header, err := req.FormFile("file")
+		if err != nil {
+			http.Error(resp, "missing file field", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+
+		// never trust the client's name with directories in it
+		dst, err := os.CreateTemp(dir, "upload-*-"+filepath.Base(header.Filename))
+		if err != nil {
+			requestLogger(req).Error("Something went wrong while creating upload file", "err", err)
+			http.Error(resp, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		defer dst.Close()
+
+		size, err := io.Copy(dst, file)
+		if err != nil {
+			requestLogger(req).Error("Something went wrong while saving upload", "err", err)
+			os.Remove(dst.Name())
+			http.Error(resp, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		WriteJSON(resp, http.StatusCreated, uploadResponse{Filename: filepath.Base(dst.Name()), Size: size})
+	})
+}
+
 /*
 	Run The Server : go run http_server.go
 
@@ -35,9 +913,46 @@ func headers(resp http.ResponseWriter, req *http.Request) {
 	==============================
 	raja@raja-Latitude-3460:~/Documents/coding/golang/go-by-examples$ curl http://localhost:8080/hello
 	Hello, World!
-	raja@raja-Latitude-3460:~/Documents/coding/golang/go-by-examples$ curl http://localhost:8080/headers
+	raja@raja-Latitude-3460:~/Documents/coding/golang/go-by-examples$ curl -u gopher:gopher http://localhost:8080/headers
 	Header key: User-Agent, value : [curl/7.68.0]
+	Header key: Authorization, value : [Basic Z29waGVyOmdvcGhlcg==]
 	Header key: Accept, value : [*/ /*] (modified form / to // to escape the comment)
 raja@raja-Latitude-3460:~/Documents/coding/golang/go-by-examples$
 
+	A panicking handler answers 500 tagged with the request ID, and the stack
+	is logged under the same ID:
+	$ curl -i -H 'X-Request-ID: 4f2a9c1e' http://localhost:8080/panic
+	HTTP/1.1 500 Internal Server Error
+	Content-Type: text/plain; charset=utf-8
+	Vary: Accept-Encoding
+	X-Content-Type-Options: nosniff
+	X-Request-Id: 4f2a9c1e
+	Content-Length: 44
+
+	Internal Server Error (request id 4f2a9c1e)
+
+	server log:
+	time=2026-10-14T17:33:12.402Z level=ERROR msg="panic while serving request" request_id=4f2a9c1e route="GET /panic" panic="something bad happened" stack="goroutine 11 [running]:\nruntime/debug.Stack()\n..."
+	time=2026-10-14T17:33:12.402Z level=INFO msg=request request_id=4f2a9c1e method=GET path=/panic status=500 duration=169.62µs
+
+	On SIGTERM the server drains first: /readyz fails for -drain-grace while
+	requests are still served, then it shuts down. A second signal skips the
+	wait.
+	$ go run http_server.go -drain-grace 2s
+	time=2026-10-14T17:34:07.118Z level=INFO msg="server listening" addr=http://127.0.0.1:8080
+	time=2026-10-14T17:34:08.341Z level=INFO msg="draining, signal again to force" signal=terminated grace=2s
+	time=2026-10-14T17:34:08.564Z level=INFO msg=request method=GET path=/readyz status=503 duration=23.947µs
+	time=2026-10-14T17:34:10.341Z level=INFO msg="server shutdown completed"
+
+	After a /hello, an unknown path and a /panic:
+	$ curl http://localhost:8080/metrics
+	http_requests_total 3
+	http_requests_in_flight 1
+	http_responses_total{class="1xx"} 0
+	http_responses_total{class="2xx"} 1
+	http_responses_total{class="3xx"} 0
+	http_responses_total{class="4xx"} 1
+	http_responses_total{class="5xx"} 1
+	http_panics_total 1
+
 */
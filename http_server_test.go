@@ -0,0 +1,863 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"flag"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestServeWaitsForInFlightRequests(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen : %v", err)
+	}
+
+	started := make(chan struct{})
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		io.WriteString(w, "done")
+	})}
+
+	stop := make(chan os.Signal, 1)
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(server, ln, stop, 0)
+	}()
+
+	type result struct {
+		body string
+		err  error
+	}
+	got := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			got <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		got <- result{string(body), err}
+	}()
+
+	<-started
+	stop <- syscall.SIGTERM
+
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("serve returned %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown did not complete")
+	}
+
+	res := <-got
+	if res.err != nil || res.body != "done" {
+		t.Errorf("slow request got %q, %v, want it to finish", res.body, res.err)
+	}
+}
+
+func TestServeTwoSignalDrain(t *testing.T) {
+	var logs bytes.Buffer
+	defer func(orig *slog.Logger) { logger = orig }(logger)
+	logger = newLogger("text", &logs)
+	SetReady(true)
+	defer SetReady(false)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen : %v", err)
+	}
+	base := "http://" + ln.Addr().String()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/readyz", readyz)
+	server := &http.Server{Handler: mux}
+
+	// a grace far longer than the test, so only the second signal can end it
+	stop := make(chan os.Signal, 1)
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(server, ln, stop, time.Hour)
+	}()
+
+	readyzStatus := func() int {
+		resp, err := http.Get(base + "/readyz")
+		if err != nil {
+			t.Fatalf("GET /readyz : %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if got := readyzStatus(); got != http.StatusOK {
+		t.Fatalf("before the signal: got /readyz %d, want 200", got)
+	}
+
+	stop <- syscall.SIGTERM
+
+	// readiness flips first, while the server still answers
+	deadline := time.Now().Add(5 * time.Second)
+	for readyzStatus() != http.StatusServiceUnavailable {
+		if time.Now().After(deadline) {
+			t.Fatal("/readyz never started failing after the first signal")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case err := <-served:
+		t.Fatalf("serve returned %v while draining, want it to keep serving", err)
+	default:
+	}
+
+	stop <- syscall.SIGTERM
+
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("serve returned %v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the second signal did not close the server")
+	}
+	if !strings.Contains(logs.String(), "while draining, closing server now") {
+		t.Errorf("got logs %q, want the forced close logged", logs.String())
+	}
+	if _, err := http.Get(base + "/readyz"); err == nil {
+		t.Error("got a response after close, want the listener gone")
+	}
+}
+
+func TestMetricsCounts(t *testing.T) {
+	defer func(orig *requestMetrics) { metrics = orig }(metrics)
+	metrics = &requestMetrics{}
+	defer func(orig *slog.Logger) { logger = orig }(logger)
+	logger = newLogger("text", io.Discard)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) { panic("boom") })
+	mux.HandleFunc("/metrics", serveMetrics)
+	h := Chain(mux, Logging, Recover)
+
+	panicsBefore := panics.Total()
+	for _, path := range []string{"/ok", "/ok", "/ok", "/missing", "/panic"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	want := map[string]int64{
+		"http_requests_total":               5,
+		"http_requests_in_flight":           1, // the /metrics request itself
+		`http_responses_total{class="1xx"}`: 0,
+		`http_responses_total{class="2xx"}`: 3,
+		`http_responses_total{class="4xx"}`: 1,
+		`http_responses_total{class="5xx"}`: 1,
+		"http_panics_total":                 panicsBefore + 1,
+	}
+	got := make(map[string]int64)
+	for _, line := range strings.Split(strings.TrimSpace(rec.Body.String()), "\n") {
+		name, value, ok := strings.Cut(line, " ")
+		n, err := strconv.ParseInt(value, 10, 64)
+		if !ok || err != nil {
+			t.Fatalf("got line %q, want \"name value\"", line)
+		}
+		got[name] = n
+	}
+	for name, n := range want {
+		if got[name] != n {
+			t.Errorf("%s: got %d, want %d", name, got[name], n)
+		}
+	}
+
+	// once answered, /metrics itself is counted and no longer in flight
+	if metrics.total.Load() != 6 || metrics.inFlight.Load() != 0 {
+		t.Errorf("after: got total %d, in flight %d, want 6, 0", metrics.total.Load(), metrics.inFlight.Load())
+	}
+}
+
+func TestRecoverAnswers500(t *testing.T) {
+	var logs bytes.Buffer
+	defer func(orig *slog.Logger) { logger = orig }(logger)
+	logger = newLogger("text", &logs)
+
+	h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	t.Run("client id", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+		req.Header.Set("X-Request-ID", "4f2a9c1e")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("got status %d, want 500", rec.Code)
+		}
+		if want := "Internal Server Error (request id 4f2a9c1e)\n"; rec.Body.String() != want {
+			t.Errorf("got body %q, want %q", rec.Body.String(), want)
+		}
+		if got := rec.Header().Get("X-Request-ID"); got != "4f2a9c1e" {
+			t.Errorf("got X-Request-ID %q, want the client's", got)
+		}
+	})
+
+	t.Run("generated id", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+		id := rec.Header().Get("X-Request-ID")
+		if len(id) != 16 {
+			t.Fatalf("got X-Request-ID %q, want 16 hex characters", id)
+		}
+		if !strings.Contains(rec.Body.String(), "(request id "+id+")") {
+			t.Errorf("got body %q, want it to carry %s", rec.Body.String(), id)
+		}
+		if !strings.Contains(logs.String(), "request_id="+id) {
+			t.Errorf("got logs %q, want the stack logged under %s", logs.String(), id)
+		}
+	})
+}
+
+func TestRecoverCountsPanics(t *testing.T) {
+	defer func(orig *slog.Logger) { logger = orig }(logger)
+	logger = newLogger("text", io.Discard)
+
+	h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ok" {
+			panic("boom")
+		}
+	}))
+
+	total := panics.Total()
+	a, b := panics.Route("GET /count-a"), panics.Route("GET /count-b")
+	for _, path := range []string{"/count-a", "/count-a", "/count-b", "/ok"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := panics.Total() - total; got != 3 {
+		t.Errorf("total: got %d more, want 3", got)
+	}
+	if got := panics.Route("GET /count-a") - a; got != 2 {
+		t.Errorf("/count-a: got %d more, want 2", got)
+	}
+	if got := panics.Route("GET /count-b") - b; got != 1 {
+		t.Errorf("/count-b: got %d more, want 1", got)
+	}
+}
+
+func TestRecoverRepanicsAbort(t *testing.T) {
+	h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	total := panics.Total()
+	rec := httptest.NewRecorder()
+	func() {
+		defer func() {
+			if r := recover(); r != http.ErrAbortHandler {
+				t.Errorf("got panic %v, want http.ErrAbortHandler re-raised", r)
+			}
+		}()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/abort", nil))
+	}()
+
+	if got := panics.Total(); got != total {
+		t.Errorf("got total %d, want %d: an abort is not counted", got, total)
+	}
+	if rec.Code != http.StatusOK || rec.Body.Len() != 0 {
+		t.Errorf("got %d %q, want nothing written", rec.Code, rec.Body.String())
+	}
+}
+
+func TestStatusRecorder(t *testing.T) {
+	var tests = []struct {
+		name    string
+		handler http.HandlerFunc
+		want    int
+	}{
+		{"not found", func(w http.ResponseWriter, r *http.Request) { http.NotFound(w, r) }, http.StatusNotFound},
+		{"created", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusCreated) }, http.StatusCreated},
+		{"write only", func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "hi") }, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := newStatusRecorder(httptest.NewRecorder())
+			tt.handler(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			if rec.status != tt.want {
+				t.Errorf("got status %d, want %d", rec.status, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConfigAppliesTimeouts(t *testing.T) {
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	cfg, err := parseConfig(fs, []string{"-addr", "127.0.0.1:0", "-read-timeout", "2s", "-write-timeout", "3s"})
+	if err != nil {
+		t.Fatalf("parseConfig failed : %v", err)
+	}
+
+	server := newServer(cfg, http.NotFoundHandler())
+	if server.Addr != "127.0.0.1:0" {
+		t.Errorf("got addr %q, want 127.0.0.1:0", server.Addr)
+	}
+	if server.ReadTimeout != 2*time.Second || server.WriteTimeout != 3*time.Second {
+		t.Errorf("got read %v, write %v, want 2s and 3s", server.ReadTimeout, server.WriteTimeout)
+	}
+}
+
+func TestParseConfigRejectsBadFlags(t *testing.T) {
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if _, err := parseConfig(fs, []string{"-read-timeout", "soon"}); err == nil {
+		t.Error("parseConfig accepted an invalid duration")
+	}
+}
+
+func TestServerTime(t *testing.T) {
+	rec := httptest.NewRecorder()
+	serverTime(rec, httptest.NewRequest(http.MethodGet, "/time", nil))
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got Content-Type %q, want application/json", ct)
+	}
+	var got timeResponse
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decoding /time : %v", err)
+	}
+	parsed, err := time.Parse(time.RFC3339, got.RFC3339)
+	if err != nil {
+		t.Fatalf("RFC3339 field %q : %v", got.RFC3339, err)
+	}
+	if parsed.Unix() != got.Unix {
+		t.Errorf("Unix %d and RFC3339 %q disagree", got.Unix, got.RFC3339)
+	}
+	if d := time.Since(parsed); d < -time.Second || d > 5*time.Second {
+		t.Errorf("server time %v is %v away from now", parsed, d)
+	}
+}
+
+func TestBasicAuth(t *testing.T) {
+	h := BasicAuth("gopher", "secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "welcome")
+	}))
+
+	var tests = []struct {
+		name       string
+		user, pass string
+		setAuth    bool
+		want       int
+	}{
+		{"no credentials", "", "", false, http.StatusUnauthorized},
+		{"wrong password", "gopher", "guess", true, http.StatusUnauthorized},
+		{"longer password", "gopher", "secret-and-more", true, http.StatusUnauthorized},
+		{"shorter password", "gopher", "sec", true, http.StatusUnauthorized},
+		{"correct", "gopher", "secret", true, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/headers", nil)
+			if tt.setAuth {
+				req.SetBasicAuth(tt.user, tt.pass)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("got status %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without a WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestGzip(t *testing.T) {
+	var logs bytes.Buffer
+	defer func(orig *slog.Logger) { logger = orig }(logger)
+	logger = newLogger("text", &logs)
+
+	body := strings.Repeat("compress me ", 100)
+	srv := httptest.NewServer(Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/not-modified":
+			w.WriteHeader(http.StatusNotModified)
+		case "/no-content":
+			w.WriteHeader(http.StatusNoContent)
+		case "/partial":
+			w.WriteHeader(http.StatusPartialContent)
+			io.WriteString(w, body[:10])
+		default:
+			io.WriteString(w, body)
+		}
+	})))
+	defer srv.Close()
+
+	var tests = []struct {
+		name        string
+		method      string
+		path        string
+		accept      string
+		wantGzip    bool
+		wantStatus  int
+		wantContent string
+	}{
+		{"gzip accepted", http.MethodGet, "/", "gzip", true, http.StatusOK, body},
+		{"plain", http.MethodGet, "/", "", false, http.StatusOK, body},
+		{"not modified", http.MethodGet, "/not-modified", "gzip", false, http.StatusNotModified, ""},
+		{"no content", http.MethodGet, "/no-content", "gzip", false, http.StatusNoContent, ""},
+		{"partial content", http.MethodGet, "/partial", "gzip", false, http.StatusPartialContent, body[:10]},
+		{"gzip refused", http.MethodGet, "/", "gzip;q=0", false, http.StatusOK, body},
+		{"head", http.MethodHead, "/", "gzip", false, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, srv.URL+tt.path, nil)
+			if tt.accept != "" {
+				// setting it ourselves stops the transport from decompressing
+				req.Header.Set("Accept-Encoding", tt.accept)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed : %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			gotGzip := resp.Header.Get("Content-Encoding") == "gzip"
+			if gotGzip != tt.wantGzip {
+				t.Fatalf("got Content-Encoding %q, want gzip %v", resp.Header.Get("Content-Encoding"), tt.wantGzip)
+			}
+
+			var reader io.Reader = resp.Body
+			if gotGzip {
+				gz, err := gzip.NewReader(resp.Body)
+				if err != nil {
+					t.Fatalf("reading gzip body : %v", err)
+				}
+				reader = gz
+			}
+			got, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("reading body : %v", err)
+			}
+			if string(got) != tt.wantContent {
+				t.Errorf("got %d bytes of body, want %d", len(got), len(tt.wantContent))
+			}
+		})
+	}
+
+	if strings.Contains(logs.String(), "gzip writer") {
+		t.Errorf("closing the gzip writer failed : %s", logs.String())
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	var tests = []struct {
+		lines []string
+		want  bool
+	}{
+		{[]string{"gzip"}, true},
+		{[]string{"deflate, gzip;q=0.5"}, true},
+		{[]string{"GZIP"}, true},
+		{[]string{"gzip;q=0"}, false},
+		{[]string{"gzip; q=0.0"}, false},
+		{[]string{"x-notgzip"}, false},
+		{[]string{"*"}, true},
+		{[]string{"*, gzip;q=0"}, false},
+		{[]string{"*;q=0"}, false},
+		{[]string{"br", "gzip"}, true},
+		{nil, false},
+	}
+
+	for _, tt := range tests {
+		header := http.Header{}
+		for _, line := range tt.lines {
+			header.Add("Accept-Encoding", line)
+		}
+		if got := acceptsGzip(header); got != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.lines, got, tt.want)
+		}
+	}
+}
+
+func TestGzipFlushes(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "first\n")
+		w.(http.Flusher).Flush()
+		<-release
+		io.WriteString(w, "second\n")
+	})))
+	defer srv.Close()
+	defer close(release)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed : %v", err)
+	}
+	defer resp.Body.Close()
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		t.Fatalf("reading gzip body : %v", err)
+	}
+	// the handler is still blocked, so this only arrives if Flush got it out
+	line, err := bufio.NewReader(gz).ReadString('\n')
+	if err != nil || line != "first\n" {
+		t.Errorf("got %q, %v, want the flushed first line", line, err)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	defer SetReady(false)
+
+	var tests = []struct {
+		ready bool
+		want  int
+	}{
+		{false, http.StatusServiceUnavailable},
+		{true, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		SetReady(tt.ready)
+		rec := httptest.NewRecorder()
+		readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if rec.Code != tt.want {
+			t.Errorf("ready %v : got status %d, want %d", tt.ready, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestStaticHandler(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "static")
+	os.MkdirAll(filepath.Join(dir, "docs"), 0755)
+	os.MkdirAll(filepath.Join(dir, "site"), 0755)
+	os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello static"), 0644)
+	os.WriteFile(filepath.Join(dir, "docs", "a.txt"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(dir, "site", "index.html"), []byte("<h1>site</h1>"), 0644)
+	os.WriteFile(filepath.Join(root, "secret.txt"), []byte("secret"), 0644)
+
+	h := StaticHandler("/static/", dir)
+
+	var tests = []struct {
+		name     string
+		path     string
+		want     int
+		wantBody string
+	}{
+		{"file", "/static/hello.txt", http.StatusOK, "hello static"},
+		{"index", "/static/site/", http.StatusOK, "<h1>site</h1>"},
+		{"no listing", "/static/docs/", http.StatusNotFound, ""},
+		{"traversal", "/static/../secret.txt", http.StatusNotFound, ""},
+		{"encoded traversal", "/static/%2e%2e/secret.txt", http.StatusNotFound, ""},
+		{"missing", "/static/nope.txt", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// straight to the handler, so no ServeMux cleans up the path first
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			body := rec.Body.String()
+
+			if rec.Code != tt.want {
+				t.Errorf("got status %d, want %d", rec.Code, tt.want)
+			}
+			if strings.Contains(body, "secret") || strings.Contains(body, "a.txt") {
+				t.Errorf("leaked %q", body)
+			}
+			if tt.wantBody != "" && body != tt.wantBody {
+				t.Errorf("got body %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func multipartFile(t *testing.T, name string, content []byte) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write(content)
+	mw.Close()
+	return &body, mw.FormDataContentType()
+}
+
+func TestUploadHandler(t *testing.T) {
+	dir := t.TempDir()
+	h := UploadHandler(dir, 1024)
+
+	t.Run("small file", func(t *testing.T) {
+		body, contentType := multipartFile(t, "../../notes.txt", []byte("some notes"))
+		req := httptest.NewRequest(http.MethodPost, "/upload", body)
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("got status %d : %s", rec.Code, rec.Body.String())
+		}
+		var got uploadResponse
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		saved, err := os.ReadFile(filepath.Join(dir, got.Filename))
+		if err != nil || string(saved) != "some notes" || got.Size != int64(len(saved)) {
+			t.Errorf("saved %q (%d bytes reported), %v", saved, got.Size, err)
+		}
+	})
+
+	t.Run("oversized", func(t *testing.T) {
+		body, contentType := multipartFile(t, "big.bin", bytes.Repeat([]byte("x"), 4096))
+		req := httptest.NewRequest(http.MethodPost, "/upload", body)
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("got status %d, want 413", rec.Code)
+		}
+	})
+
+	t.Run("wrong method", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/upload", nil))
+		if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != http.MethodPost {
+			t.Errorf("got status %d, Allow %q", rec.Code, rec.Header().Get("Allow"))
+		}
+	})
+}
+
+func TestRateLimit(t *testing.T) {
+	request := func(h http.Handler, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("burst then 429", func(t *testing.T) {
+		h := RateLimit(1, 3)(http.HandlerFunc(hello))
+		for i := 0; i < 3; i++ {
+			if rec := request(h, "10.0.0.1:1234"); rec.Code != http.StatusOK {
+				t.Fatalf("request %d within the burst got %d", i+1, rec.Code)
+			}
+		}
+		rec := request(h, "10.0.0.1:1234")
+		if rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("request past the burst got %d, want 429", rec.Code)
+		}
+		if got := rec.Header().Get("Retry-After"); got != "1" {
+			t.Errorf("got Retry-After %q, want 1", got)
+		}
+
+		// another client has a bucket of its own
+		if rec := request(h, "10.0.0.2:1234"); rec.Code != http.StatusOK {
+			t.Errorf("other IP got %d, want 200", rec.Code)
+		}
+	})
+
+	t.Run("zero rate", func(t *testing.T) {
+		h := RateLimit(0, 1)(http.HandlerFunc(hello))
+		request(h, "10.0.0.1:1234")
+		rec := request(h, "10.0.0.1:1234")
+		if rec.Code != http.StatusTooManyRequests {
+			t.Errorf("got status %d, want 429", rec.Code)
+		}
+		if got := rec.Header().Get("Retry-After"); got != "" {
+			t.Errorf("got Retry-After %q, want none", got)
+		}
+	})
+
+	t.Run("parseConfig rejects zero", func(t *testing.T) {
+		fs := flag.NewFlagSet("server", flag.ContinueOnError)
+		if _, err := parseConfig(fs, []string{"-rate-limit", "0"}); err == nil {
+			t.Error("parseConfig accepted -rate-limit 0")
+		}
+	})
+}
+
+func TestCORS(t *testing.T) {
+	h := CORS(CORSConfig{
+		AllowedOrigins: []string{"https://example.com"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders: []string{"Authorization"},
+	})(http.HandlerFunc(hello))
+
+	var tests = []struct {
+		name        string
+		method      string
+		origin      string
+		preflight   bool
+		wantStatus  int
+		wantOrigin  string
+		wantMethods string
+	}{
+		{"preflight", http.MethodOptions, "https://example.com", true, http.StatusNoContent, "https://example.com", "GET, POST"},
+		{"allowed", http.MethodGet, "https://example.com", false, http.StatusOK, "https://example.com", ""},
+		{"disallowed", http.MethodGet, "https://evil.example", false, http.StatusOK, "", ""},
+		{"disallowed preflight", http.MethodOptions, "https://evil.example", true, http.StatusNoContent, "", ""},
+		{"same origin", http.MethodGet, "", false, http.StatusOK, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/hello", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.preflight {
+				req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("got Allow-Origin %q, want %q", got, tt.wantOrigin)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Methods"); got != tt.wantMethods {
+				t.Errorf("got Allow-Methods %q, want %q", got, tt.wantMethods)
+			}
+			if tt.preflight && rec.Body.Len() != 0 {
+				t.Errorf("preflight reached the handler : %q", rec.Body.String())
+			}
+			if got := rec.Header().Get("Vary"); got != "Origin" {
+				t.Errorf("got Vary %q, want %q", got, "Origin")
+			}
+		})
+	}
+}
+
+func TestCORSAnyOriginNoVary(t *testing.T) {
+	h := CORS(CORSConfig{AllowedOrigins: []string{"*"}})(http.HandlerFunc(hello))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hello", nil))
+	if got := rec.Header().Get("Vary"); got != "" {
+		t.Errorf("got Vary %q without Origin, want none when any origin is allowed", got)
+	}
+}
+
+func TestSelfSignedCertServesHTTPS(t *testing.T) {
+	cert, err := selfSignedCert([]string{"127.0.0.1", "localhost"})
+	if err != nil {
+		t.Fatalf("selfSignedCert failed : %v", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(hello))
+	srv.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	srv.StartTLS()
+	defer srv.Close()
+
+	// trust exactly our certificate, as a developer would
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+
+	resp, err := client.Get(srv.URL + "/hello")
+	if err != nil {
+		t.Fatalf("HTTPS GET failed : %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "Hello, World!\n" {
+		t.Errorf("got %d %q", resp.StatusCode, body)
+	}
+}
+
+func TestParseConfigFromEnv(t *testing.T) {
+	t.Run("env defaults", func(t *testing.T) {
+		t.Setenv("SERVER_ADDR", "127.0.0.1:9090")
+		t.Setenv("READ_TIMEOUT", "1s")
+		fs := flag.NewFlagSet("server", flag.ContinueOnError)
+		cfg, err := parseConfig(fs, nil)
+		if err != nil {
+			t.Fatalf("parseConfig failed : %v", err)
+		}
+		if cfg.Addr != "127.0.0.1:9090" || cfg.ReadTimeout != time.Second || cfg.WriteTimeout != 10*time.Second {
+			t.Errorf("got %s %v %v", cfg.Addr, cfg.ReadTimeout, cfg.WriteTimeout)
+		}
+	})
+
+	t.Run("flag beats env", func(t *testing.T) {
+		t.Setenv("READ_TIMEOUT", "1s")
+		fs := flag.NewFlagSet("server", flag.ContinueOnError)
+		cfg, err := parseConfig(fs, []string{"-read-timeout", "2s"})
+		if err != nil || cfg.ReadTimeout != 2*time.Second {
+			t.Errorf("got %v, %v, want 2s", cfg.ReadTimeout, err)
+		}
+	})
+
+	t.Run("malformed env", func(t *testing.T) {
+		t.Setenv("WRITE_TIMEOUT", "soon")
+		fs := flag.NewFlagSet("server", flag.ContinueOnError)
+		_, err := parseConfig(fs, nil)
+		if err == nil || !strings.Contains(err.Error(), "WRITE_TIMEOUT") {
+			t.Errorf("got error %v, want one naming WRITE_TIMEOUT", err)
+		}
+	})
+}
+
+func TestLoggingJSON(t *testing.T) {
+	defer func(orig *slog.Logger) { logger = orig }(logger)
+	var out bytes.Buffer
+	logger = newLogger("json", &out)
+
+	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+	req.Header.Set("X-Request-ID", "abc123")
+	Logging(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), req)
+
+	var record map[string]any
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("log line %q is not JSON : %v", out.String(), err)
+	}
+	for key, want := range map[string]any{"level": "INFO", "msg": "request", "request_id": "abc123", "path": "/missing", "status": float64(404)} {
+		if record[key] != want {
+			t.Errorf("got %s = %v, want %v", key, record[key], want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Clock is what HumanizeSince reads the current time from. Clock and
+// RealClock are copied from tickers.go, as every example is its own program,
+// so the FakeClock in fakeclock_test.go serves the tests of both.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type RealClock struct{}
+
+func (RealClock) Now() time.Time                         { return time.Now() }
+func (RealClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+const day = 24 * time.Hour
+
+var humanUnits = []struct {
+	size   time.Duration
+	short  string
+	plural string
+}{
+	{day, "d", "days"},
+	{time.Hour, "h", "hours"},
+	{time.Minute, "m", "minutes"},
+	{time.Second, "s", "seconds"},
+}
+
+// HumanizeDuration renders d with its two largest units, e.g. "5d4h" or
+// "2h3m". Anything under a second is "just now", and negative durations
+// (something in the future) read as "in 2h3m".
+func HumanizeDuration(d time.Duration) string {
+	if d < 0 {
+		if -d < time.Second {
+			return "just now"
+		}
+		return "in " + HumanizeDuration(-d)
+	}
+	if d < time.Second {
+		return "just now"
+	}
+
+	out := ""
+	parts := 0
+	for _, u := range humanUnits {
+		if n := d / u.size; n > 0 && parts < 2 {
+			out += fmt.Sprintf("%d%s", n, u.short)
+			d -= n * u.size
+			parts++
+		} else if parts > 0 {
+			// stop at the first gap, "1d5s" says less than "1d"
+			break
+		}
+	}
+	return out
+}
+
+// HumanizeSince describes t relative to the clock's now, like "3 minutes ago".
+func HumanizeSince(t time.Time, clock Clock) string {
+	d := clock.Now().Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	if d < time.Second {
+		return "just now"
+	}
+
+	for _, u := range humanUnits {
+		if n := int64(d / u.size); n > 0 {
+			unit := u.plural
+			if n == 1 {
+				unit = unit[:len(unit)-1]
+			}
+			if future {
+				return fmt.Sprintf("in %d %s", n, unit)
+			}
+			return fmt.Sprintf("%d %s ago", n, unit)
+		}
+	}
+	return "just now"
+}
+
+func main() {
+
+	p := fmt.Println
+
+	p(HumanizeDuration(0))
+	p(HumanizeDuration(300 * time.Millisecond))
+	p(HumanizeDuration(2*time.Hour + 3*time.Minute + 4*time.Second))
+	p(HumanizeDuration(5*day + 4*time.Hour))
+	p(HumanizeDuration(day + 5*time.Second))
+	p(HumanizeDuration(-90 * time.Minute))
+
+	clock := RealClock{}
+	p(HumanizeSince(time.Now().Add(-3*time.Minute), clock))
+	p(HumanizeSince(time.Now().Add(-time.Hour), clock))
+	// a minute over, as the time until then shrinks while we format it
+	p(HumanizeSince(time.Now().Add(2*day+time.Minute), clock))
+	p(HumanizeSince(time.Now(), clock))
+}
+
+/*
+	$ go run humanize.go
+	just now
+	just now
+	2h3m
+	5d4h
+	1d
+	in 1h30m
+	3 minutes ago
+	1 hour ago
+	in 2 days
+	just now
+*/
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHumanizeDuration(t *testing.T) {
+	var tests = []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "just now"},
+		{300 * time.Millisecond, "just now"},
+		{-300 * time.Millisecond, "just now"},
+		{45 * time.Second, "45s"},
+		{2*time.Hour + 3*time.Minute + 4*time.Second, "2h3m"},
+		{5*day + 4*time.Hour, "5d4h"},
+		{day + 5*time.Second, "1d"},
+		{-90 * time.Minute, "in 1h30m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.d.String(), func(t *testing.T) {
+			if got := HumanizeDuration(tt.d); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHumanizeSince(t *testing.T) {
+	clock := NewFakeClock(time.Date(2021, time.June, 8, 12, 0, 0, 0, time.UTC))
+	posted := clock.Now()
+
+	if got := HumanizeSince(posted, clock); got != "just now" {
+		t.Errorf("at once got %q, want just now", got)
+	}
+	clock.Advance(3 * time.Minute)
+	if got := HumanizeSince(posted, clock); got != "3 minutes ago" {
+		t.Errorf("after 3m got %q", got)
+	}
+	clock.Advance(57 * time.Minute)
+	if got := HumanizeSince(posted, clock); got != "1 hour ago" {
+		t.Errorf("after 1h got %q", got)
+	}
+	if got := HumanizeSince(clock.Now().Add(2*day), clock); got != "in 2 days" {
+		t.Errorf("two days ahead got %q", got)
+	}
+}
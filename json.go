@@ -2,8 +2,12 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 )
 
 type response1 struct {
@@ -16,6 +20,78 @@ type response2 struct {
 	Fruits []string `json:"fruits"`
 }
 
+// Timestamp is a time.Time that is encoded as an RFC3339 string, or null
+// for the zero time.
+type Timestamp struct {
+	time.Time
+}
+
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Format(time.RFC3339))
+}
+
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		t.Time = time.Time{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("timestamp must be a string : %w", err)
+	}
+	if s == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return fmt.Errorf("timestamp %q is not RFC3339 : %w", s, err)
+	}
+	t.Time = parsed
+	return nil
+}
+
+type Event struct {
+	Name string    `json:"name"`
+	At   Timestamp `json:"at"`
+}
+
+// ToJSON marshals v, indented with two spaces when pretty is set.
+func ToJSON(v any, pretty bool) ([]byte, error) {
+	if pretty {
+		return json.MarshalIndent(v, "", "  ")
+	}
+	return json.Marshal(v)
+}
+
+func FromJSON(data []byte, out any) error {
+	return json.Unmarshal(data, out)
+}
+
+// DecodeNDJSON decodes newline-delimited JSON from r one record at a time and
+// hands each to fn, so only the current record is held in memory. It stops at
+// the first record that doesn't decode, with an error giving its index
+// (counting from 0), or at the first error from fn.
+func DecodeNDJSON[T any](r io.Reader, fn func(T) error) error {
+	dec := json.NewDecoder(r)
+	for i := 0; ; i++ {
+		var record T
+		if err := dec.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("record %d : %w", i, err)
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
 func main() {
 
 	bolB, _ := json.Marshal(true)
@@ -75,6 +151,40 @@ func main() {
 	enc := json.NewEncoder(os.Stdout)
 	d := map[string]int{"apple": 5, "lettuce": 7}
 	enc.Encode(d)
+
+	launch := Event{Name: "launch", At: Timestamp{time.Date(2021, time.June, 8, 11, 40, 0, 0, time.UTC)}}
+	evtB, _ := ToJSON(launch, true)
+	fmt.Println(string(evtB))
+
+	var evt Event
+	err := FromJSON([]byte(`{"name":"launch","at":"2021-06-08T19:40:00+08:00"}`), &evt)
+	fmt.Println(evt.Name, evt.At.UTC(), err)
+
+	pending, _ := ToJSON(Event{Name: "pending"}, false)
+	fmt.Println(string(pending))
+
+	err = FromJSON([]byte(`{"name":"bad","at":"June 8th"}`), &evt)
+	fmt.Println(err)
+
+	stream := `{"name":"build","at":"2021-06-08T10:00:00Z"}
+{"name":"test","at":"2021-06-08T10:05:00Z"}
+{"name":"deploy","at":"2021-06-08T10:20:00Z"}
+`
+	err = DecodeNDJSON(strings.NewReader(stream), func(e Event) error {
+		fmt.Println(e.Name, e.At.Format(time.Kitchen))
+		return nil
+	})
+	fmt.Println(err)
+
+	broken := `{"name":"build","at":"2021-06-08T10:00:00Z"}
+{"name":"test",
+{"name":"deploy","at":"2021-06-08T10:20:00Z"}
+`
+	err = DecodeNDJSON(strings.NewReader(broken), func(e Event) error {
+		fmt.Println(e.Name)
+		return nil
+	})
+	fmt.Println(err)
 }
 
 /*
@@ -93,5 +203,18 @@ func main() {
 	{1 [apple peach]}
 	apple
 	{"apple":5,"lettuce":7}
+	{
+	  "name": "launch",
+	  "at": "2021-06-08T11:40:00Z"
+	}
+	launch 2021-06-08 11:40:00 +0000 UTC <nil>
+	{"name":"pending","at":null}
+	timestamp "June 8th" is not RFC3339 : parsing time "June 8th" as "2006-01-02T15:04:05Z07:00": cannot parse "June 8th" as "2006"
+	build 10:00AM
+	test 10:05AM
+	deploy 10:20AM
+	<nil>
+	build
+	record 1 : invalid character '{' looking for beginning of object key string
 	raja@raja-Latitude-3460:~/Documents/coding/golang/go-by-examples$
 */
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimestampRoundTrip(t *testing.T) {
+	at := time.Date(2021, time.June, 8, 11, 40, 0, 0, time.FixedZone("IST", 5*3600+1800))
+	in := Event{Name: "launch", At: Timestamp{at}}
+
+	data, err := ToJSON(in, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"launch","at":"2021-06-08T11:40:00+05:30"}`; string(data) != want {
+		t.Errorf("marshal: got %s, want %s", data, want)
+	}
+
+	var out Event
+	if err := FromJSON(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Name != in.Name || !out.At.Equal(at) {
+		t.Errorf("unmarshal: got %+v, want %+v", out, in)
+	}
+}
+
+func TestTimestampZero(t *testing.T) {
+	data, err := ToJSON(Event{Name: "unset"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"unset","at":null}`; string(data) != want {
+		t.Errorf("marshal: got %s, want %s", data, want)
+	}
+
+	for _, in := range []string{`{"at":null}`, `{"at":""}`} {
+		out := Event{At: Timestamp{time.Now()}}
+		if err := FromJSON([]byte(in), &out); err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		if !out.At.IsZero() {
+			t.Errorf("%s: got %v, want the zero time", in, out.At)
+		}
+	}
+}
+
+func TestTimestampInvalid(t *testing.T) {
+	for _, in := range []string{
+		`{"at":"2021-06-08"}`,
+		`{"at":"yesterday"}`,
+		`{"at":1623152400}`,
+	} {
+		var out Event
+		if err := FromJSON([]byte(in), &out); err == nil {
+			t.Errorf("%s: got %+v, want an error", in, out)
+		}
+	}
+}
+
+func TestDecodeNDJSON(t *testing.T) {
+	t.Run("valid stream", func(t *testing.T) {
+		in := `{"name":"launch","at":"2021-06-08T11:40:00Z"}
+{"name":"landing","at":null}
+
+{"name":"debrief","at":"2021-06-09T08:00:00Z"}
+`
+		var names []string
+		err := DecodeNDJSON(strings.NewReader(in), func(e Event) error {
+			names = append(names, e.Name)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"launch", "landing", "debrief"}; !slices.Equal(names, want) {
+			t.Errorf("got %v, want %v", names, want)
+		}
+	})
+
+	t.Run("malformed middle record", func(t *testing.T) {
+		in := `{"name":"a"}
+{"name":"b"}
+{"name":"c",}
+{"name":"d"}
+`
+		var names []string
+		err := DecodeNDJSON(strings.NewReader(in), func(e Event) error {
+			names = append(names, e.Name)
+			return nil
+		})
+		var syntaxErr *json.SyntaxError
+		if !errors.As(err, &syntaxErr) || !strings.HasPrefix(err.Error(), "record 2 : ") {
+			t.Errorf("got %v, want a syntax error for record 2", err)
+		}
+		if want := []string{"a", "b"}; !slices.Equal(names, want) {
+			t.Errorf("got %v, want only the records before the bad one", names)
+		}
+	})
+
+	t.Run("bad field value", func(t *testing.T) {
+		in := `{"name":"a","at":"yesterday"}`
+		err := DecodeNDJSON(strings.NewReader(in), func(Event) error { return nil })
+		if err == nil || !strings.HasPrefix(err.Error(), "record 0 : ") {
+			t.Errorf("got %v, want an error for record 0", err)
+		}
+	})
+
+	t.Run("fn error stops", func(t *testing.T) {
+		errStop := errors.New("stop")
+		calls := 0
+		err := DecodeNDJSON(strings.NewReader("{}\n{}\n{}\n"), func(Event) error {
+			calls++
+			return errStop
+		})
+		if err != errStop || calls != 1 {
+			t.Errorf("got %v after %d calls, want %v after 1", err, calls, errStop)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// leakSettle is how long AssertNoLeaks gives goroutines to wind down, e.g.
+// after a cancel, before calling them leaked.
+const leakSettle = time.Second
+
+// leakedGoroutines polls until the goroutine count is back to before or
+// settle runs out, and returns how many extra goroutines are left.
+func leakedGoroutines(before int, settle time.Duration) int {
+	deadline := time.Now().Add(settle)
+	for {
+		leaked := runtime.NumGoroutine() - before
+		if leaked <= 0 {
+			return 0
+		}
+		if time.Now().After(deadline) {
+			return leaked
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// AssertNoLeaks fails t if it ends with more goroutines running than when
+// AssertNoLeaks was called. Call it first, so it checks after the test's
+// other cleanups have run. The count is process wide, so it can't be used in
+// tests that call t.Parallel.
+func AssertNoLeaks(t *testing.T) {
+	t.Helper()
+	before := runtime.NumGoroutine()
+	t.Cleanup(func() {
+		if leaked := leakedGoroutines(before, leakSettle); leaked > 0 {
+			buf := make([]byte, 1<<16)
+			buf = buf[:runtime.Stack(buf, true)]
+			t.Errorf("%d goroutine(s) leaked:\n%s", leaked, buf)
+		}
+	})
+}
+
+func TestAssertNoLeaks(t *testing.T) {
+	AssertNoLeaks(t)
+
+	done := make(chan struct{})
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(done)
+	}()
+	<-done
+}
+
+func TestLeakedGoroutinesDetectsLeak(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	release := make(chan struct{})
+	defer close(release)
+	go func() { <-release }()
+
+	if leaked := leakedGoroutines(before, 50*time.Millisecond); leaked != 1 {
+		t.Errorf("leakedGoroutines = %d; want 1", leaked)
+	}
+}
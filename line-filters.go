@@ -3,23 +3,92 @@ package main
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 )
 
-func main() {
+// eachLine calls fn with every line of r, without the trailing newline.
+// bufio.Reader is used instead of bufio.Scanner so lines longer than the
+// scanner's 64K token limit still come through whole.
+func eachLine(r io.Reader, fn func(line string) error) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if len(line) > 0 {
+			line = strings.TrimSuffix(line, "\n")
+			line = strings.TrimSuffix(line, "\r")
+			if ferr := fn(line); ferr != nil {
+				return ferr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
 
-	scanner := bufio.NewScanner(os.Stdin)
+// FilterLines copies the lines of r for which predicate is true to w, like grep.
+func FilterLines(r io.Reader, w io.Writer, predicate func(string) bool) error {
+	bw := bufio.NewWriter(w)
+	err := eachLine(r, func(line string) error {
+		if !predicate(line) {
+			return nil
+		}
+		_, err := fmt.Fprintln(bw, line)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
 
-	for scanner.Scan() {
+// ToUpper copies r to w with every line upper-cased, like tr a-z A-Z.
+func ToUpper(r io.Reader, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	err := eachLine(r, func(line string) error {
+		_, err := fmt.Fprintln(bw, strings.ToUpper(line))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
 
-		ucl := strings.ToUpper(scanner.Text())
+func main() {
 
-		fmt.Println(ucl)
+	if len(os.Args) > 1 {
+		// grep: only keep the lines containing the first argument
+		pattern := os.Args[1]
+		err := FilterLines(os.Stdin, os.Stdout, func(line string) bool {
+			return strings.Contains(line, pattern)
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	if err := scanner.Err(); err != nil {
+	if err := ToUpper(os.Stdin, os.Stdout); err != nil {
 		fmt.Fprintln(os.Stderr, "error:", err)
 		os.Exit(1)
 	}
 }
+
+/*
+	$ printf 'hello\nfilter lines\ngopher\n' | go run line-filters.go
+	HELLO
+	FILTER LINES
+	GOPHER
+
+	With an argument only the matching lines are kept:
+	$ printf 'hello\nfilter lines\ngopher\n' | go run line-filters.go er
+	filter lines
+	gopher
+*/
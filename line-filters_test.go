@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLineFilters(t *testing.T) {
+	long := strings.Repeat("a", 100*1024)
+
+	var tests = []struct {
+		name      string
+		in        string
+		wantUpper string
+		wantGrep  string
+	}{
+		{"multi-line", "hello\nfilter lines\ngopher\n", "HELLO\nFILTER LINES\nGOPHER\n", "filter lines\ngopher\n"},
+		{"no trailing newline", "hello\ngopher", "HELLO\nGOPHER\n", "gopher\n"},
+		{"crlf", "hello\r\ngopher\r\n", "HELLO\nGOPHER\n", "gopher\n"},
+		{"empty", "", "", ""},
+		{"over 64K", long + "er\nshort\n", strings.ToUpper(long) + "ER\nSHORT\n", long + "er\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var upper bytes.Buffer
+			if err := ToUpper(strings.NewReader(tt.in), &upper); err != nil {
+				t.Fatalf("ToUpper failed : %v", err)
+			}
+			if upper.String() != tt.wantUpper {
+				t.Errorf("ToUpper got %d bytes, want %d", upper.Len(), len(tt.wantUpper))
+			}
+
+			var grep bytes.Buffer
+			err := FilterLines(strings.NewReader(tt.in), &grep, func(line string) bool {
+				return strings.Contains(line, "er")
+			})
+			if err != nil {
+				t.Fatalf("FilterLines failed : %v", err)
+			}
+			if grep.String() != tt.wantGrep {
+				t.Errorf("FilterLines got %d bytes, want %d", grep.Len(), len(tt.wantGrep))
+			}
+		})
+	}
+}
@@ -1,6 +1,36 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+)
+
+func Keys[K comparable, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func Values[K comparable, V any](m map[K]V) []V {
+	values := make([]V, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	return values
+}
+
+// Merge copies src into dst, which must not be nil. Without overwrite, keys
+// already present in dst keep their value.
+func Merge[K comparable, V any](dst, src map[K]V, overwrite bool) {
+	for k, v := range src {
+		if _, exists := dst[k]; exists && !overwrite {
+			continue
+		}
+		dst[k] = v
+	}
+}
 
 func main() {
 
@@ -24,6 +54,19 @@ func main() {
 
 	n := map[string]int{"foo": 1, "bar": 2}
 	fmt.Println("map:", n)
+
+	keys := Keys(n)
+	sort.Strings(keys) // map order is random, sort for a stable output
+	fmt.Println("keys:", keys)
+	fmt.Println("values:", len(Values(n)))
+
+	Merge(n, map[string]int{"foo": 10, "baz": 3}, false)
+	fmt.Println("merge:", n)
+	Merge(n, map[string]int{"foo": 10}, true)
+	fmt.Println("merge overwrite:", n)
+
+	var empty map[string]int
+	fmt.Println("nil keys:", len(Keys(empty)), len(Values(empty)))
 }
 
 /*
@@ -34,4 +77,9 @@ func main() {
 	map: map[k1:7]
 	prs: false
 	map: map[bar:2 foo:1]
+	keys: [bar foo]
+	values: 2
+	merge: map[bar:2 baz:3 foo:1]
+	merge overwrite: map[bar:2 baz:3 foo:10]
+	nil keys: 0 0
 */
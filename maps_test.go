@@ -0,0 +1,53 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestKeysValues(t *testing.T) {
+	m := map[string]int{"foo": 1, "bar": 2}
+
+	keys := Keys(m)
+	slices.Sort(keys)
+	if want := []string{"bar", "foo"}; !slices.Equal(keys, want) {
+		t.Errorf("Keys got %v, want %v", keys, want)
+	}
+	values := Values(m)
+	slices.Sort(values)
+	if want := []int{1, 2}; !slices.Equal(values, want) {
+		t.Errorf("Values got %v, want %v", values, want)
+	}
+
+	var empty map[string]int
+	if got := Keys(empty); got == nil || len(got) != 0 {
+		t.Errorf("Keys of nil map got %#v, want an empty slice", got)
+	}
+	if got := Values(empty); got == nil || len(got) != 0 {
+		t.Errorf("Values of nil map got %#v, want an empty slice", got)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	var tests = []struct {
+		name      string
+		src       map[string]int
+		overwrite bool
+		want      map[string]int
+	}{
+		{"keep existing", map[string]int{"foo": 10, "baz": 3}, false, map[string]int{"foo": 1, "bar": 2, "baz": 3}},
+		{"overwrite", map[string]int{"foo": 10, "baz": 3}, true, map[string]int{"foo": 10, "bar": 2, "baz": 3}},
+		{"nil src", nil, true, map[string]int{"foo": 1, "bar": 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := map[string]int{"foo": 1, "bar": 2}
+			Merge(dst, tt.src, tt.overwrite)
+			if !maps.Equal(dst, tt.want) {
+				t.Errorf("got %v, want %v", dst, tt.want)
+			}
+		})
+	}
+}
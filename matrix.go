@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Transpose turns rows into columns. Jagged input has no transpose, so like
+// empty input it gives back an empty matrix.
+func Transpose[T any](m [][]T) [][]T {
+	if len(m) == 0 || len(m[0]) == 0 {
+		return [][]T{}
+	}
+
+	cols := len(m[0])
+	for _, row := range m {
+		if len(row) != cols {
+			return [][]T{}
+		}
+	}
+
+	t := make([][]T, cols)
+	for j := range t {
+		t[j] = make([]T, len(m))
+		for i := range m {
+			t[j][i] = m[i][j]
+		}
+	}
+	return t
+}
+
+func MultiplyInt(a, b [][]int) ([][]int, error) {
+	if len(a) == 0 || len(b) == 0 {
+		return nil, errors.New("cannot multiply empty matrices")
+	}
+	for _, row := range a {
+		if len(row) != len(b) {
+			return nil, fmt.Errorf("dimension mismatch : %d columns in a, %d rows in b", len(row), len(b))
+		}
+	}
+	cols := len(b[0])
+	for _, row := range b {
+		if len(row) != cols {
+			return nil, errors.New("b is not a rectangular matrix")
+		}
+	}
+
+	c := make([][]int, len(a))
+	for i := range a {
+		c[i] = make([]int, cols)
+		for j := 0; j < cols; j++ {
+			for k := range b {
+				c[i][j] += a[i][k] * b[k][j]
+			}
+		}
+	}
+	return c, nil
+}
+
+func main() {
+
+	a := [][]int{{1, 2, 3}, {4, 5, 6}}
+	fmt.Println("a:  ", a)
+
+	t := Transpose(a)
+	fmt.Println("a^T:", t)
+
+	c, err := MultiplyInt(a, t)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("a x a^T:", c)
+
+	_, err = MultiplyInt(a, a)
+	fmt.Println("a x a:", err)
+
+	fmt.Println("jagged^T:", Transpose([][]int{{1, 2}, {3}}))
+}
+
+/*
+	$ go run matrix.go
+	a:   [[1 2 3] [4 5 6]]
+	a^T: [[1 4] [2 5] [3 6]]
+	a x a^T: [[14 32] [32 77]]
+	a x a: dimension mismatch : 3 columns in a, 2 rows in b
+	jagged^T: []
+*/
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTranspose(t *testing.T) {
+	var tests = []struct {
+		name string
+		m    [][]int
+		want [][]int
+	}{
+		{"2x3", [][]int{{1, 2, 3}, {4, 5, 6}}, [][]int{{1, 4}, {2, 5}, {3, 6}}},
+		{"1x1", [][]int{{7}}, [][]int{{7}}},
+		{"empty", nil, [][]int{}},
+		{"jagged", [][]int{{1, 2}, {3}}, [][]int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Transpose(tt.m); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiplyInt(t *testing.T) {
+	a := [][]int{{1, 2, 3}, {4, 5, 6}}
+
+	got, err := MultiplyInt(a, Transpose(a))
+	if err != nil {
+		t.Fatalf("MultiplyInt failed : %v", err)
+	}
+	if want := [][]int{{14, 32}, {32, 77}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if _, err := MultiplyInt(a, a); err == nil {
+		t.Error("multiplying 2x3 by 2x3 did not fail")
+	}
+	if _, err := MultiplyInt(nil, a); err == nil {
+		t.Error("multiplying an empty matrix did not fail")
+	}
+}
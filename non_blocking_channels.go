@@ -2,6 +2,29 @@ package main
 
 import "fmt"
 
+// TrySend sends v on ch if a receiver or buffer slot is ready, and reports
+// whether it did.
+func TrySend[T any](ch chan<- T, v T) bool {
+	select {
+	case ch <- v:
+		return true
+	default:
+		return false
+	}
+}
+
+// TryRecv receives from ch if a value is ready. A closed channel also
+// reports false.
+func TryRecv[T any](ch <-chan T) (T, bool) {
+	select {
+	case v, ok := <-ch:
+		return v, ok
+	default:
+		var zero T
+		return zero, false
+	}
+}
+
 func main() {
 	messages := make(chan string)
 	signals := make(chan bool)
@@ -29,4 +52,21 @@ func main() {
 	default:
 		fmt.Println("no activity")
 	}
+
+	buffered := make(chan int, 1)
+	fmt.Println(TryRecv(buffered))
+	fmt.Println(TrySend(buffered, 1))
+	fmt.Println(TrySend(buffered, 2))
+	fmt.Println(TryRecv(buffered))
 }
+
+/*
+	$ go run non_blocking_channels.go
+	no message received
+	no message sent
+	no activity
+	0 false
+	true
+	false
+	1 true
+*/
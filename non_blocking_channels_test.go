@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestTrySendTryRecv(t *testing.T) {
+	ch := make(chan int, 1)
+
+	if _, ok := TryRecv(ch); ok {
+		t.Error("TryRecv on an empty channel reported a value")
+	}
+	if !TrySend(ch, 7) {
+		t.Error("TrySend into a free buffer slot failed")
+	}
+	if TrySend(ch, 8) {
+		t.Error("TrySend into a full channel succeeded")
+	}
+	if v, ok := TryRecv(ch); !ok || v != 7 {
+		t.Errorf("TryRecv got %d, %v, want 7, true", v, ok)
+	}
+
+	close(ch)
+	if v, ok := TryRecv(ch); ok || v != 0 {
+		t.Errorf("TryRecv on a closed channel got %d, %v, want 0, false", v, ok)
+	}
+}
@@ -1,10 +1,38 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 )
 
+// parseAll parses every element of ss with parse. Values that fail to parse
+// are left as zero and all the failures are joined into one error that names
+// their indexes.
+func parseAll[T any](ss []string, parse func(string) (T, error)) ([]T, error) {
+	values := make([]T, len(ss))
+	var errs []error
+	for i, s := range ss {
+		v, err := parse(s)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("index %d : %w", i, err))
+			continue
+		}
+		values[i] = v
+	}
+	return values, errors.Join(errs...)
+}
+
+func ParseInts(ss []string) ([]int, error) {
+	return parseAll(ss, strconv.Atoi)
+}
+
+func ParseFloats(ss []string) ([]float64, error) {
+	return parseAll(ss, func(s string) (float64, error) {
+		return strconv.ParseFloat(s, 64)
+	})
+}
+
 func main() {
 
 	f, _ := strconv.ParseFloat("1.234", 64)
@@ -24,4 +52,25 @@ func main() {
 
 	_, e := strconv.Atoi("wat")
 	fmt.Println(e)
+
+	ints, e := ParseInts([]string{"1", "two", "3", "4.0"})
+	fmt.Println(ints)
+	fmt.Println(e)
+
+	floats, e := ParseFloats([]string{"1.5", "2e3"})
+	fmt.Println(floats, e)
 }
+
+/*
+	$ go run number-parsing.go
+	1.234
+	123
+	456
+	789
+	135
+	strconv.Atoi: parsing "wat": invalid syntax
+	[1 0 3 0]
+	index 1 : strconv.Atoi: parsing "two": invalid syntax
+	index 3 : strconv.Atoi: parsing "4.0": invalid syntax
+	[1.5 2000] <nil>
+*/
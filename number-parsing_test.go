@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseInts(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      []string
+		want    []int
+		wantErr []string
+	}{
+		{"valid", []string{"1", "-2", "300"}, []int{1, -2, 300}, nil},
+		{"mixed", []string{"1", "two", "3", "4.5"}, []int{1, 0, 3, 0}, []string{"index 1", "index 3"}},
+		{"empty", []string{}, []int{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseInts(tt.in)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("got error %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, strconv.ErrSyntax) {
+				t.Errorf("got %v, want it to wrap strconv.ErrSyntax", err)
+			}
+			for _, want := range tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), want) {
+					t.Errorf("got %v, want it to name %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFloats(t *testing.T) {
+	got, err := ParseFloats([]string{"1.5", "1e3", "x"})
+	if want := []float64{1.5, 1000, 0}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if err == nil || !strings.Contains(err.Error(), "index 2") {
+		t.Errorf("got %v, want an error naming index 2", err)
+	}
+}
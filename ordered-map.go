@@ -0,0 +1,96 @@
+package main
+
+import (
+	"container/list"
+	"fmt"
+)
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// OrderedMap remembers the order keys were first set in. Setting an existing
+// key keeps its place, while deleting and setting it again moves it to the end.
+type OrderedMap[K comparable, V any] struct {
+	index map[K]*list.Element
+	order *list.List
+}
+
+func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{index: make(map[K]*list.Element), order: list.New()}
+}
+
+func (m *OrderedMap[K, V]) Set(k K, v V) {
+	if el, ok := m.index[k]; ok {
+		el.Value.(*entry[K, V]).value = v
+		return
+	}
+	m.index[k] = m.order.PushBack(&entry[K, V]{key: k, value: v})
+}
+
+func (m *OrderedMap[K, V]) Get(k K) (V, bool) {
+	el, ok := m.index[k]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return el.Value.(*entry[K, V]).value, true
+}
+
+func (m *OrderedMap[K, V]) Delete(k K) {
+	if el, ok := m.index[k]; ok {
+		m.order.Remove(el)
+		delete(m.index, k)
+	}
+}
+
+func (m *OrderedMap[K, V]) Len() int {
+	return len(m.index)
+}
+
+// Range calls fn for each entry in insertion order until fn returns false.
+func (m *OrderedMap[K, V]) Range(fn func(K, V) bool) {
+	for el := m.order.Front(); el != nil; el = el.Next() {
+		e := el.Value.(*entry[K, V])
+		if !fn(e.key, e.value) {
+			return
+		}
+	}
+}
+
+func main() {
+
+	m := NewOrderedMap[string, int]()
+	m.Set("one", 1)
+	m.Set("two", 2)
+	m.Set("three", 3)
+	m.Set("one", 11)
+
+	show := func(label string) {
+		fmt.Print(label, ":")
+		m.Range(func(k string, v int) bool {
+			fmt.Printf(" %s=%d", k, v)
+			return true
+		})
+		fmt.Println()
+	}
+	show("set")
+
+	m.Delete("one")
+	show("delete")
+
+	m.Set("one", 1)
+	show("set again")
+
+	v, ok := m.Get("two")
+	fmt.Println("get:", v, ok)
+}
+
+/*
+	$ go run ordered-map.go
+	set: one=11 two=2 three=3
+	delete: two=2 three=3
+	set again: two=2 three=3 one=1
+	get: 2 true
+*/
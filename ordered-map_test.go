@@ -0,0 +1,49 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func orderedKeys(m *OrderedMap[string, int]) []string {
+	var keys []string
+	m.Range(func(k string, v int) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+func TestOrderedMapOrder(t *testing.T) {
+	m := NewOrderedMap[string, int]()
+	m.Set("one", 1)
+	m.Set("two", 2)
+	m.Set("three", 3)
+
+	m.Set("one", 11)
+	if got, want := orderedKeys(m), []string{"one", "two", "three"}; !slices.Equal(got, want) {
+		t.Errorf("after updating one got %v, want %v", got, want)
+	}
+	if v, ok := m.Get("one"); !ok || v != 11 {
+		t.Errorf("Get(one) = %d, %v, want 11, true", v, ok)
+	}
+
+	m.Delete("one")
+	if _, ok := m.Get("one"); ok || m.Len() != 2 {
+		t.Errorf("one still present after Delete, len %d", m.Len())
+	}
+
+	m.Set("one", 1)
+	if got, want := orderedKeys(m), []string{"two", "three", "one"}; !slices.Equal(got, want) {
+		t.Errorf("after delete and set got %v, want %v", got, want)
+	}
+
+	var first []string
+	m.Range(func(k string, v int) bool {
+		first = append(first, k)
+		return false
+	})
+	if len(first) != 1 {
+		t.Errorf("Range kept going after fn returned false : %v", first)
+	}
+}
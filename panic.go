@@ -1,12 +1,107 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 )
 
+// CreateFileEnsuringDirs creates the missing parent directories of path before
+// creating the file itself. Errors still match os.ErrPermission or
+// os.ErrNotExist, but say which of the two went wrong.
+func CreateFileEnsuringDirs(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, classifyCreateErr(path, err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, classifyCreateErr(path, err)
+	}
+	return f, nil
+}
+
+func classifyCreateErr(path string, err error) error {
+	switch {
+	case errors.Is(err, os.ErrPermission):
+		return fmt.Errorf("no permission to create %s : %w", path, err)
+	case errors.Is(err, os.ErrNotExist):
+		return fmt.Errorf("parent of %s does not exist : %w", path, err)
+	default:
+		return fmt.Errorf("creating %s : %w", path, err)
+	}
+}
+
+// FormatPanic describes a value returned by recover together with the stack
+// of the panicking goroutine, leaving out the runtime's own frames and the
+// deferred function that recovered. It has to be called from that deferred
+// function, while the stack is still there.
+func FormatPanic(recovered interface{}) string {
+	var b strings.Builder
+
+	switch v := recovered.(type) {
+	case error:
+		fmt.Fprintf(&b, "panic: %v (%T)\n", v, v)
+	case string:
+		fmt.Fprintf(&b, "panic: %s\n", v)
+	default:
+		fmt.Fprintf(&b, "panic: %#v (%T)\n", v, v)
+	}
+
+	pcs := make([]uintptr, 64)
+	// skip runtime.Callers and FormatPanic itself
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+
+	var stack []runtime.Frame
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.gopanic" {
+			// what came before is the deferred function that recovered
+			stack = stack[:0]
+		} else if !strings.HasPrefix(frame.Function, "runtime.") {
+			stack = append(stack, frame)
+		}
+		if !more {
+			break
+		}
+	}
+
+	for _, frame := range stack {
+		fmt.Fprintf(&b, "\t%s\n\t\t%s:%d\n", frame.Function, frame.File, frame.Line)
+	}
+	return b.String()
+}
+
+// safely runs fn and turns a panic into a logged, formatted stack trace.
+func safely(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Print(FormatPanic(r))
+		}
+	}()
+	fn()
+}
+
 func main() {
-	_, err := os.Create("/tmp/abc/xyz/file.txt")
+	safely(func() {
+		var m map[string]int
+		m["boom"] = 1
+	})
+	safely(func() { panic("something bad happened") })
+	safely(func() { panic(struct{ Code int }{42}) })
+
+	f, err := CreateFileEnsuringDirs("/tmp/abc/ensured/file.txt")
+	if err != nil {
+		log.Fatalf("Something went wrong while creating file , %v", err)
+	}
+	f.Close()
+	log.Printf("created file : %s", f.Name())
+
+	_, err = os.Create("/tmp/abc/xyz/file.txt")
 
 	if err != nil {
 		// panic(err)
@@ -45,4 +140,29 @@ func main() {
 
 	2021/06/06 10:37:15 Something went wrong while creating file , open /tmp/abc/xyz/file.txt: no such file or directory
 	exit status 1
+
+	FormatPanic, logged from the safely recover boundary:
+	=====================================================
+
+	2026/10/14 17:41:03 panic: assignment to entry in nil map (runtime.plainError)
+		main.main.func1
+			/home/raja/Documents/coding/golang/go-by-examples/panic.go:92
+		main.safely
+			/home/raja/Documents/coding/golang/go-by-examples/panic.go:86
+		main.main
+			/home/raja/Documents/coding/golang/go-by-examples/panic.go:90
+	2026/10/14 17:41:03 panic: something bad happened
+		main.main.func2
+			/home/raja/Documents/coding/golang/go-by-examples/panic.go:94
+		main.safely
+			/home/raja/Documents/coding/golang/go-by-examples/panic.go:86
+		main.main
+			/home/raja/Documents/coding/golang/go-by-examples/panic.go:94
+	2026/10/14 17:41:03 panic: struct { Code int }{Code:42} (struct { Code int })
+		main.main.func3
+			/home/raja/Documents/coding/golang/go-by-examples/panic.go:95
+		main.safely
+			/home/raja/Documents/coding/golang/go-by-examples/panic.go:86
+		main.main
+			/home/raja/Documents/coding/golang/go-by-examples/panic.go:95
 */
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateFileEnsuringDirs(t *testing.T) {
+	t.Run("nested dirs", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "a", "b", "c", "file.txt")
+		f, err := CreateFileEnsuringDirs(path)
+		if err != nil {
+			t.Fatalf("CreateFileEnsuringDirs failed : %v", err)
+		}
+		f.Close()
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("file was not created : %v", err)
+		}
+	})
+
+	t.Run("read-only parent", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root ignores directory permissions")
+		}
+		parent := filepath.Join(t.TempDir(), "locked")
+		if err := os.Mkdir(parent, 0555); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := CreateFileEnsuringDirs(filepath.Join(parent, "sub", "file.txt"))
+		if !errors.Is(err, os.ErrPermission) {
+			t.Fatalf("got error %v, want os.ErrPermission", err)
+		}
+		if !strings.Contains(err.Error(), "no permission") {
+			t.Errorf("got error %q, want it classified as a permission problem", err)
+		}
+	})
+}
+
+// panicker is a named function, so it can be looked for in the stack.
+func panicker(v any) {
+	panic(v)
+}
+
+// formatPanicOf panics with v in panicker and returns what FormatPanic makes
+// of it.
+func formatPanicOf(v any) (s string) {
+	defer func() {
+		s = FormatPanic(recover())
+	}()
+	panicker(v)
+	return ""
+}
+
+func TestFormatPanic(t *testing.T) {
+	var tests = []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"string", "something bad happened", "panic: something bad happened\n"},
+		{"error", errors.New("disk full"), "panic: disk full (*errors.errorString)\n"},
+		{"other", 42, "panic: 42 (int)\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatPanicOf(tt.value)
+			if !strings.HasPrefix(got, tt.want) {
+				t.Errorf("got %q, want it to start with %q", got, tt.want)
+			}
+
+			// under go test the package of a file list is command-line-arguments
+			lines := strings.Split(got, "\n")
+			if len(lines) < 2 || lines[1] != "\tcommand-line-arguments.panicker" {
+				t.Errorf("got stack\n%s\nwant it to start at panicker", got)
+			}
+			if !strings.Contains(got, "command-line-arguments.formatPanicOf\n") {
+				t.Errorf("got stack\n%s\nwant the caller of panicker in it", got)
+			}
+			if strings.Contains(got, "\truntime.") || strings.Contains(got, "formatPanicOf.func") {
+				t.Errorf("got stack\n%s\nwant no runtime frames or the deferred function", got)
+			}
+		})
+	}
+}
@@ -3,9 +3,57 @@ package main
 import (
 	"fmt"
 	"math/rand"
+	"sync"
 	"time"
 )
 
+// rng backs RandInt, RandString and Shuffle. A *rand.Rand isn't safe for
+// concurrent use, hence the mutex.
+var (
+	rngMu sync.Mutex
+	rng   = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// SetRandSource swaps the source used by the helpers below, e.g. to
+// rand.NewSource(42) for reproducible output in tests.
+func SetRandSource(src rand.Source) {
+	rngMu.Lock()
+	defer rngMu.Unlock()
+	rng = rand.New(src)
+}
+
+// RandInt returns a random int in [lo, hi]. It panics if lo > hi.
+func RandInt(lo, hi int) int {
+	if lo > hi {
+		panic(fmt.Sprintf("RandInt: lo %d is greater than hi %d", lo, hi))
+	}
+	rngMu.Lock()
+	defer rngMu.Unlock()
+	return lo + rng.Intn(hi-lo+1)
+}
+
+const randAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// RandString returns n random letters and digits. It is not suitable for
+// secrets, use crypto/rand for those.
+func RandString(n int) string {
+	rngMu.Lock()
+	defer rngMu.Unlock()
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = randAlphabet[rng.Intn(len(randAlphabet))]
+	}
+	return string(b)
+}
+
+func Shuffle[T any](s []T) {
+	rngMu.Lock()
+	defer rngMu.Unlock()
+	rng.Shuffle(len(s), func(i, j int) {
+		s[i], s[j] = s[j], s[i]
+	})
+}
+
 func main() {
 
 	fmt.Print(rand.Intn(100), ",")
@@ -34,4 +82,25 @@ func main() {
 	r3 := rand.New(s3)
 	fmt.Print(r3.Intn(100), ",")
 	fmt.Print(r3.Intn(100))
+	fmt.Println()
+
+	SetRandSource(rand.NewSource(42))
+	fmt.Println(RandInt(1, 6), RandInt(1, 6), RandInt(7, 7))
+	fmt.Println(RandString(8))
+	deck := []string{"A", "K", "Q", "J", "10"}
+	Shuffle(deck)
+	fmt.Println(deck)
 }
+
+/*
+	$ go run random-numbers.go
+	10,22
+	0.37768783646832443
+	9.204777167511438,8.69165215581189
+	10,40
+	5,87
+	5,87
+	6 6 7
+	INvNSQTZ
+	[Q J A 10 K]
+*/
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// sample draws from every helper, so two runs from the same seed can be
+// compared as a whole.
+func sample() (ints []int, s string, shuffled []int) {
+	for i := 0; i < 10; i++ {
+		ints = append(ints, RandInt(0, 100))
+	}
+	shuffled = []int{1, 2, 3, 4, 5, 6, 7, 8}
+	Shuffle(shuffled)
+	return ints, RandString(16), shuffled
+}
+
+func TestSeededDeterminism(t *testing.T) {
+	SetRandSource(rand.NewSource(42))
+	ints1, s1, shuffled1 := sample()
+	SetRandSource(rand.NewSource(42))
+	ints2, s2, shuffled2 := sample()
+
+	if !reflect.DeepEqual(ints1, ints2) || s1 != s2 || !reflect.DeepEqual(shuffled1, shuffled2) {
+		t.Errorf("same seed, different output: %v %q %v vs %v %q %v",
+			ints1, s1, shuffled1, ints2, s2, shuffled2)
+	}
+}
+
+func TestRandIntBounds(t *testing.T) {
+	SetRandSource(rand.NewSource(1))
+	seen := make(map[int]bool)
+	for i := 0; i < 1000; i++ {
+		n := RandInt(-2, 2)
+		if n < -2 || n > 2 {
+			t.Fatalf("got %d, want it in [-2, 2]", n)
+		}
+		seen[n] = true
+	}
+	if len(seen) != 5 {
+		t.Errorf("got %v, want every value in [-2, 2] to come up", seen)
+	}
+
+	if got := RandInt(7, 7); got != 7 {
+		t.Errorf("lo == hi: got %d, want 7", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("lo > hi: got no panic")
+		}
+	}()
+	RandInt(3, 2)
+}
+
+func TestRandString(t *testing.T) {
+	for _, n := range []int{0, 1, 64} {
+		s := RandString(n)
+		if len(s) != n {
+			t.Errorf("got length %d, want %d", len(s), n)
+		}
+		for _, c := range s {
+			if !strings.ContainsRune(randAlphabet, c) {
+				t.Errorf("got %q in %q, want only letters and digits", c, s)
+			}
+		}
+	}
+}
+
+func TestShuffleKeepsElements(t *testing.T) {
+	s := []int{5, 3, 1, 4, 2}
+	Shuffle(s)
+	slices.Sort(s)
+	if want := []int{1, 2, 3, 4, 5}; !slices.Equal(s, want) {
+		t.Errorf("got %v, want %v", s, want)
+	}
+}
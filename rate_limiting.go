@@ -1,10 +1,73 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"sync"
 	"time"
 )
 
+// Limiter is a token bucket: it holds up to burst tokens and refills rate
+// tokens per second. Each Allow or Wait spends one token.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter returns a Limiter that starts with a full bucket.
+func NewLimiter(rate float64, burst int) *Limiter {
+	return &Limiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// reserve takes a token if one is available, otherwise it reports how long
+// until the next one.
+func (l *Limiter) reserve(now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return true, 0
+	}
+	if l.rate <= 0 {
+		return false, time.Duration(math.MaxInt64)
+	}
+	return false, time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// Allow reports whether a token was available, without blocking.
+func (l *Limiter) Allow() bool {
+	ok, _ := l.reserve(time.Now())
+	return ok
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		ok, wait := l.reserve(time.Now())
+		if ok {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
 func main() {
 
 	requests := make(chan int, 5)
@@ -41,4 +104,46 @@ func main() {
 		<-burstyLimiter
 		fmt.Println("request", req, time.Now())
 	}
+
+	fmt.Println()
+	tokens := NewLimiter(5, 3)
+	for i := 1; i <= 5; i++ {
+		fmt.Println("allow", i, tokens.Allow())
+	}
+
+	start := time.Now()
+	for i := 1; i <= 3; i++ {
+		if err := tokens.Wait(context.Background()); err != nil {
+			fmt.Println("wait:", err)
+		}
+		fmt.Println("waited request", i, time.Since(start).Round(10*time.Millisecond))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	fmt.Println("wait:", tokens.Wait(ctx))
 }
+
+/*
+	$ go run rate_limiting.go
+	request 1 2026-10-14 17:19:36.198025191 +0000 UTC m=+0.200369809
+	request 2 2026-10-14 17:19:36.398679036 +0000 UTC m=+0.401023629
+	request 3 2026-10-14 17:19:36.598092593 +0000 UTC m=+0.600437199
+	request 4 2026-10-14 17:19:36.798522913 +0000 UTC m=+0.800867506
+	request 5 2026-10-14 17:19:36.997836566 +0000 UTC m=+1.000181159
+	request 1 2026-10-14 17:19:36.997933369 +0000 UTC m=+1.000277961
+	request 2 2026-10-14 17:19:36.997939546 +0000 UTC m=+1.000284139
+	request 3 2026-10-14 17:19:36.997943597 +0000 UTC m=+1.000288201
+	request 4 2026-10-14 17:19:37.198264596 +0000 UTC m=+1.200609189
+	request 5 2026-10-14 17:19:37.398610246 +0000 UTC m=+1.400954848
+
+	allow 1 true
+	allow 2 true
+	allow 3 true
+	allow 4 false
+	allow 5 false
+	waited request 1 200ms
+	waited request 2 400ms
+	waited request 3 600ms
+	wait: context deadline exceeded
+*/
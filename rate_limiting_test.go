@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiterDrainAndRefill(t *testing.T) {
+	l := NewLimiter(10, 3)
+	now := l.last
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.reserve(now); !ok {
+			t.Fatalf("token %d of the burst was refused", i+1)
+		}
+	}
+	ok, wait := l.reserve(now)
+	if ok {
+		t.Fatal("got a token from a drained bucket")
+	}
+	if wait != 100*time.Millisecond {
+		t.Errorf("got wait %v, want 100ms at 10 tokens per second", wait)
+	}
+
+	// 250ms refills two and a half tokens
+	now = now.Add(250 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.reserve(now); !ok {
+			t.Fatalf("refilled token %d was refused", i+1)
+		}
+	}
+	if ok, _ := l.reserve(now); ok {
+		t.Error("got a third token after only 250ms")
+	}
+
+	// the bucket never holds more than burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		l.reserve(now)
+	}
+	if ok, _ := l.reserve(now); ok {
+		t.Error("bucket refilled past its burst")
+	}
+}
+
+func TestLimiterWaitCancelled(t *testing.T) {
+	l := NewLimiter(0.1, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("first Wait failed : %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Wait took %v to notice the cancelled context", elapsed)
+	}
+}
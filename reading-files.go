@@ -2,10 +2,12 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"strings"
 )
 
 func check(e error) {
@@ -14,6 +16,62 @@ func check(e error) {
 	}
 }
 
+// ReadLines returns the lines of the file at path without their line
+// endings. A last line without a trailing newline is kept, and an empty file
+// gives no lines.
+func ReadLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadString('\n')
+		if len(line) > 0 {
+			lines = append(lines, strings.TrimRight(line, "\r\n"))
+		}
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// ReadChunks streams the file at path to fn in chunks of up to size bytes.
+// The slice is reused between calls, so fn must copy anything it keeps.
+func ReadChunks(path string, size int, fn func([]byte) error) error {
+	if size <= 0 {
+		return errors.New("chunk size must be positive")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	buf := make([]byte, size)
+	for {
+		n, err := io.ReadFull(f, buf)
+		if n > 0 {
+			if ferr := fn(buf[:n]); ferr != nil {
+				return ferr
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
 func main() {
 
 	dat, err := ioutil.ReadFile("errors.go")
@@ -52,4 +110,28 @@ func main() {
 	fmt.Printf("5 bytes: %s\n", string(b4))
 
 	f.Close()
+
+	lines, err := ReadLines("errors.go")
+	check(err)
+	fmt.Printf("%d lines, first: %s\n", len(lines), lines[0])
+
+	chunks, total := 0, 0
+	err = ReadChunks("errors.go", 256, func(chunk []byte) error {
+		chunks++
+		total += len(chunk)
+		return nil
+	})
+	check(err)
+	fmt.Printf("%d chunks, %d bytes\n", chunks, total)
 }
+
+/*
+	After printing errors.go, the program ends with:
+	$ go run reading-files.go | tail -6
+	5 bytes: packa
+	2 bytes @ 6: e
+	2 bytes @ 6: e
+	5 bytes: packa
+	64 lines, first: package main
+	5 chunks, 1046 bytes
+*/
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"trailing newline", "a\nb\n", []string{"a", "b"}},
+		{"no trailing newline", "a\nb", []string{"a", "b"}},
+		{"crlf", "a\r\nb\r\n", []string{"a", "b"}},
+		{"blank line kept", "a\n\nb\n", []string{"a", "", "b"}},
+		{"empty file", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "lines.txt")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadLines(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ReadLines(filepath.Join(t.TempDir(), "missing")); !os.IsNotExist(err) {
+		t.Errorf("missing file: got %v, want a not-exist error", err)
+	}
+}
+
+func TestReadChunks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chunks.txt")
+	if err := os.WriteFile(path, []byte("abcdefg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var chunks []string
+	err := ReadChunks(path, 3, func(b []byte) error {
+		chunks = append(chunks, string(b))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"abc", "def", "g"}; !slices.Equal(chunks, want) {
+		t.Errorf("got %q, want %q", chunks, want)
+	}
+
+	if err := ReadChunks(path, 0, nil); err == nil {
+		t.Error("size 0: got nil error")
+	}
+}
@@ -4,8 +4,69 @@ import (
 	"bytes"
 	"fmt"
 	"regexp"
+	"sync"
 )
 
+var regexCache = struct {
+	sync.RWMutex
+	m map[string]*regexp.Regexp
+}{m: make(map[string]*regexp.Regexp)}
+
+// CompileCached compiles pattern once and returns the same *regexp.Regexp on
+// later calls. A Regexp is safe for concurrent use, so it can be shared.
+func CompileCached(pattern string) (*regexp.Regexp, error) {
+	regexCache.RLock()
+	re, ok := regexCache.m[pattern]
+	regexCache.RUnlock()
+	if ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	regexCache.Lock()
+	defer regexCache.Unlock()
+	if cached, ok := regexCache.m[pattern]; ok {
+		return cached, nil
+	}
+	regexCache.m[pattern] = re
+	return re, nil
+}
+
+// MustMatch reports whether s matches pattern, panicking if pattern doesn't
+// compile, like regexp.MustCompile.
+func MustMatch(pattern, s string) bool {
+	re, err := CompileCached(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return re.MatchString(s)
+}
+
+// FindAllNamed returns one map per match of pattern in s, from each named
+// group to the text it captured. An invalid pattern or no match gives nil.
+func FindAllNamed(pattern, s string) []map[string]string {
+	re, err := CompileCached(pattern)
+	if err != nil {
+		return nil
+	}
+
+	var matches []map[string]string
+	for _, sub := range re.FindAllStringSubmatch(s, -1) {
+		named := make(map[string]string)
+		for i, name := range re.SubexpNames() {
+			if name != "" {
+				named[name] = sub[i]
+			}
+		}
+		matches = append(matches, named)
+	}
+	return matches
+}
+
 func main() {
 
 	match, _ := regexp.MatchString("p([a-z]+)ch", "peach")
@@ -40,4 +101,29 @@ func main() {
 	in := []byte("a peach")
 	out := r.ReplaceAllFunc(in, bytes.ToUpper)
 	fmt.Println(string(out))
+
+	fmt.Println(MustMatch(`^\d{4}-\d{2}-\d{2}$`, "2021-06-08"))
+	dates := FindAllNamed(`(?P<year>\d{4})-(?P<month>\d{2})`, "2021-06 and 2022-01")
+	fmt.Println(dates)
+	fmt.Println(FindAllNamed(`(?P<year>\d{4})`, "no dates"))
 }
+
+/*
+	$ go run regexpression.go
+	true
+	true
+	peach
+	[0 5]
+	[peach ea]
+	[0 5 1 3]
+	[peach punch pinch]
+	[[0 5 1 3] [6 11 7 9] [12 17 13 15]]
+	[peach punch]
+	true
+	p([a-z]+)ch
+	a <fruit>
+	a PEACH
+	true
+	[map[month:06 year:2021] map[month:01 year:2022]]
+	[]
+*/
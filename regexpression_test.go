@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestFindAllNamed(t *testing.T) {
+	const date = `(?P<year>\d{4})-(?P<month>\d{2})-(?P<day>\d{2})`
+	tests := []struct {
+		name    string
+		pattern string
+		s       string
+		want    []map[string]string
+	}{
+		{"two matches", date, "from 2021-06-08 to 2022-01-31", []map[string]string{
+			{"year": "2021", "month": "06", "day": "08"},
+			{"year": "2022", "month": "01", "day": "31"},
+		}},
+		{"unnamed groups are left out", `(?P<key>\w+)=(\d+)`, "a=1", []map[string]string{
+			{"key": "a"},
+		}},
+		{"no match", date, "no dates here", nil},
+		{"invalid pattern", `(?P<year>\d{4}`, "2021", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindAllNamed(tt.pattern, tt.s); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileCachedReturnsSamePointer(t *testing.T) {
+	const pattern = `p([a-z]+)ch`
+
+	first, err := CompileCached(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if re, err := CompileCached(pattern); err != nil || re != first {
+				t.Errorf("got %p, %v, want the cached %p", re, err, first)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := CompileCached(`a(b`); err == nil {
+		t.Error("invalid pattern: got nil error")
+	}
+}
+
+func TestMustMatch(t *testing.T) {
+	if !MustMatch(`p([a-z]+)ch`, "peach") {
+		t.Error("peach: got no match")
+	}
+	if MustMatch(`p([a-z]+)ch`, "apple") {
+		t.Error("apple: got a match")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("invalid pattern: got no panic")
+		}
+	}()
+	MustMatch(`a(b`, "ab")
+}
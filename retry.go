@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// ErrStopRetry can be returned (or wrapped) by the retried function to give
+// up straight away, e.g. for errors that will never go away on their own.
+var ErrStopRetry = errors.New("stop retrying")
+
+// backoffCap returns the upper bound (exclusive) of the delay before retry
+// number attempt, base*2^(attempt-1)+1, doubling only while that can't
+// overflow a time.Duration.
+func backoffCap(base time.Duration, attempt int) time.Duration {
+	backoff := base
+	for i := 1; i < attempt && backoff <= math.MaxInt64>>1; i++ {
+		backoff <<= 1
+	}
+	if backoff <= 0 {
+		return 1
+	}
+	if backoff == math.MaxInt64 {
+		return backoff
+	}
+	return backoff + 1
+}
+
+// Retry calls fn up to attempts times until it succeeds. Between attempts it
+// sleeps a random duration between 0 and base*2^attempt ("full jitter"), so
+// many clients failing together don't retry in lockstep. If ctx is done while
+// waiting, the error wraps both ctx.Err() and the last error from fn.
+func Retry(ctx context.Context, attempts int, base time.Duration, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			delay := time.Duration(rand.Int63n(int64(backoffCap(base, attempt))))
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return fmt.Errorf("gave up after %d attempts : %w, last error : %w", attempt, ctx.Err(), err)
+			case <-timer.C:
+			}
+		}
+
+		if err = fn(); err == nil {
+			return nil
+		}
+		if errors.Is(err, ErrStopRetry) {
+			return fmt.Errorf("stopped after %d attempts : %w", attempt+1, err)
+		}
+	}
+	return fmt.Errorf("failed after %d attempts : %w", attempts, err)
+}
+
+func main() {
+
+	ctx := context.Background()
+
+	calls := 0
+	err := Retry(ctx, 5, 10*time.Millisecond, func() error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf("flaky failure %d", calls)
+		}
+		return nil
+	})
+	fmt.Println("flaky:", err, "calls:", calls)
+
+	err = Retry(ctx, 3, 10*time.Millisecond, func() error {
+		return errors.New("server is down")
+	})
+	fmt.Println("permanent:", err)
+
+	err = Retry(ctx, 3, 10*time.Millisecond, func() error {
+		return fmt.Errorf("bad credentials : %w", ErrStopRetry)
+	})
+	fmt.Println("stop:", err)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = Retry(cancelled, 3, time.Second, func() error {
+		return errors.New("never succeeds")
+	})
+	fmt.Println("cancelled:", err, errors.Is(err, context.Canceled))
+}
+
+/*
+	$ go run retry.go
+	flaky: <nil> calls: 3
+	permanent: failed after 3 attempts : server is down
+	stop: stopped after 1 attempts : bad credentials : stop retrying
+	cancelled: gave up after 1 attempts : context canceled, last error : never succeeds true
+*/
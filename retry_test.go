@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	errDown := errors.New("server is down")
+
+	t.Run("succeeds on third try", func(t *testing.T) {
+		calls := 0
+		err := Retry(context.Background(), 5, time.Millisecond, func() error {
+			calls++
+			if calls < 3 {
+				return errDown
+			}
+			return nil
+		})
+		if err != nil || calls != 3 {
+			t.Errorf("got %v after %d calls, want nil after 3", err, calls)
+		}
+	})
+
+	t.Run("permanent failure", func(t *testing.T) {
+		calls := 0
+		err := Retry(context.Background(), 3, time.Millisecond, func() error {
+			calls++
+			return errDown
+		})
+		if !errors.Is(err, errDown) || calls != 3 {
+			t.Errorf("got %v after %d calls, want %v after 3", err, calls, errDown)
+		}
+	})
+
+	t.Run("ErrStopRetry", func(t *testing.T) {
+		calls := 0
+		err := Retry(context.Background(), 3, time.Millisecond, func() error {
+			calls++
+			return fmt.Errorf("bad credentials : %w", ErrStopRetry)
+		})
+		if !errors.Is(err, ErrStopRetry) || calls != 1 {
+			t.Errorf("got %v after %d calls, want ErrStopRetry after 1", err, calls)
+		}
+	})
+
+	t.Run("ctx cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		err := Retry(ctx, 3, time.Hour, func() error {
+			calls++
+			cancel()
+			return errDown
+		})
+		if !errors.Is(err, context.Canceled) || !errors.Is(err, errDown) {
+			t.Errorf("got %v, want both context.Canceled and the last error", err)
+		}
+		if calls != 1 || !strings.Contains(err.Error(), "after 1 attempts") {
+			t.Errorf("got %q after %d calls, want the attempt count", err, calls)
+		}
+	})
+}
+
+func TestBackoffCap(t *testing.T) {
+	var tests = []struct {
+		base    time.Duration
+		attempt int
+		want    time.Duration
+	}{
+		{time.Millisecond, 1, time.Millisecond + 1},
+		{time.Millisecond, 4, 8*time.Millisecond + 1},
+		{time.Hour, 100, time.Hour<<21 + 1},
+		{1, 1000, 1<<62 + 1},
+		{math.MaxInt64, 3, math.MaxInt64},
+		{0, 5, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v x %d", tt.base, tt.attempt), func(t *testing.T) {
+			if got := backoffCap(tt.base, tt.attempt); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryHugeBackoff(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := Retry(ctx, 1000, math.MaxInt64, func() error {
+		return errors.New("server is down")
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SafeMap is a counter map that can be shared between goroutines, unlike a
+// plain map which must not be written concurrently.
+type SafeMap[K comparable] struct {
+	mu     sync.RWMutex
+	counts map[K]int
+}
+
+func NewSafeMap[K comparable]() *SafeMap[K] {
+	return &SafeMap[K]{counts: make(map[K]int)}
+}
+
+func (m *SafeMap[K]) Inc(k K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[k]++
+}
+
+func (m *SafeMap[K]) Get(k K) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.counts[k]
+}
+
+// Snapshot returns a copy, so callers can't change the counts behind the lock.
+func (m *SafeMap[K]) Snapshot() map[K]int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	snapshot := make(map[K]int, len(m.counts))
+	for k, v := range m.counts {
+		snapshot[k] = v
+	}
+	return snapshot
+}
+
+func main() {
+
+	counts := NewSafeMap[string]()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				counts.Inc("hits")
+			}
+		}()
+	}
+	wg.Wait()
+
+	fmt.Println("hits:", counts.Get("hits"))
+
+	snapshot := counts.Snapshot()
+	snapshot["hits"] = 0
+	fmt.Println("snapshot changed:", snapshot, "map still:", counts.Get("hits"))
+}
+
+/*
+	$ go run safe-map.go
+	hits: 100000
+	snapshot changed: map[hits:0] map still: 100000
+*/
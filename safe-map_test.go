@@ -0,0 +1,34 @@
+package main
+
+import (
+	"sync"
+	"testing"
+)
+
+// Run with -race: go test -race safe-map.go safe-map_test.go
+func TestSafeMapConcurrentInc(t *testing.T) {
+	counts := NewSafeMap[string]()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				counts.Inc("hits")
+				counts.Get("hits")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := counts.Get("hits"); got != 100*1000 {
+		t.Errorf("got %d hits, want %d", got, 100*1000)
+	}
+
+	snapshot := counts.Snapshot()
+	snapshot["hits"] = 0
+	if got := counts.Get("hits"); got != 100*1000 {
+		t.Errorf("changing the snapshot changed the map to %d", got)
+	}
+}
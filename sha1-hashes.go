@@ -2,9 +2,68 @@ package main
 
 import (
 	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 )
 
+// HashString returns the hex digest of s under h, e.g. sha1.New().
+func HashString(s string, h hash.Hash) string {
+	h.Reset()
+	io.WriteString(h, s)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// HashFile streams the file at path through h and returns the hex digest, so
+// large files are never held in memory.
+func HashFile(path string, h hash.Hash) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h.Reset()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hashing %s : %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifyChecksum reports whether the SHA1 of the file at path matches
+// expectedHex. The digests are compared in constant time, so the time taken
+// doesn't tell how many leading bytes were right.
+func VerifyChecksum(path, expectedHex string) (bool, error) {
+	expected, err := hex.DecodeString(strings.TrimSpace(expectedHex))
+	if err != nil {
+		return false, fmt.Errorf("invalid checksum %q : %w", expectedHex, err)
+	}
+
+	digest, err := HashFile(path, sha1.New())
+	if err != nil {
+		return false, err
+	}
+	actual, _ := hex.DecodeString(digest)
+	return subtle.ConstantTimeCompare(actual, expected) == 1, nil
+}
+
+// WriteChecksumFile writes the SHA1 of path to path + ".sha1", in the
+// "<digest>  <name>" format that sha1sum -c understands.
+func WriteChecksumFile(path string) error {
+	digest, err := HashFile(path, sha1.New())
+	if err != nil {
+		return err
+	}
+	line := fmt.Sprintf("%s  %s\n", digest, filepath.Base(path))
+	return os.WriteFile(path+".sha1", []byte(line), 0644)
+}
+
 func main() {
 	s := "sha1 this string"
 
@@ -16,4 +75,48 @@ func main() {
 
 	fmt.Println(s)
 	fmt.Printf("%x\n", bs)
+
+	fmt.Println("sha1:  ", HashString(s, sha1.New()))
+	fmt.Println("sha256:", HashString(s, sha256.New()))
+
+	digest, err := HashFile("sha1-hashes.go", sha256.New())
+	if err != nil {
+		fmt.Println("hash file:", err)
+		os.Exit(1)
+	}
+	fmt.Println("sha256 sha1-hashes.go:", len(digest), "hex chars")
+
+	dir, err := os.MkdirTemp("", "checksums")
+	if err != nil {
+		fmt.Println("temp dir:", err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "data.txt")
+	os.WriteFile(path, []byte(s), 0644)
+	if err := WriteChecksumFile(path); err != nil {
+		fmt.Println("write checksum:", err)
+		os.Exit(1)
+	}
+	sidecar, _ := os.ReadFile(path + ".sha1")
+	fmt.Print("data.txt.sha1: ", string(sidecar))
+
+	expected, _, _ := strings.Cut(string(sidecar), " ")
+	fmt.Println(VerifyChecksum(path, expected))
+	fmt.Println(VerifyChecksum(path, HashString("something else", sha1.New())))
+	fmt.Println(VerifyChecksum(filepath.Join(dir, "missing.txt"), expected))
 }
+
+/*
+	$ go run sha1-hashes.go
+	sha1 this string
+	cf23df2207d99a74fbe169e3eba035e633b65d94
+	sha1:   cf23df2207d99a74fbe169e3eba035e633b65d94
+	sha256: fceab3bb749b11a43b89f21ccd28e3f5d8b38d5b23eeea960fc169ab482ee2cd
+	sha256 sha1-hashes.go: 64 hex chars
+	data.txt.sha1: cf23df2207d99a74fbe169e3eba035e633b65d94  data.txt
+	true <nil>
+	false <nil>
+	false open /tmp/checksums1907044828/missing.txt: no such file or directory
+*/
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"hash"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHashString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		h    hash.Hash
+		want string
+	}{
+		{"sha1 empty", "", sha1.New(), "da39a3ee5e6b4b0d3255bfef95601890afd80709"},
+		{"sha1 abc", "abc", sha1.New(), "a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{"sha256 abc", "abc", sha256.New(), "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HashString(tt.in, tt.h); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	// the hash is reset first, so reusing one gives the same digest
+	h := sha1.New()
+	HashString("something else", h)
+	if got, want := HashString("abc", h), "a9993e364706816aba3e25717850c26c9cd0d89d"; got != want {
+		t.Errorf("reused hash: got %s, want %s", got, want)
+	}
+}
+
+func TestHashFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "abc.txt")
+	if err := os.WriteFile(path, []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := HashFile(path, sha1.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := HashString("abc", sha1.New()); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	if _, err := HashFile(filepath.Join(t.TempDir(), "missing"), sha1.New()); !os.IsNotExist(err) {
+		t.Errorf("missing file: got %v, want a not-exist error", err)
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "abc.txt")
+	if err := os.WriteFile(path, []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name     string
+		path     string
+		expected string
+		want     bool
+		wantErr  bool
+	}{
+		{"correct", path, "a9993e364706816aba3e25717850c26c9cd0d89d", true, false},
+		{"correct, upper case with newline", path, "A9993E364706816ABA3E25717850C26C9CD0D89D\n", true, false},
+		{"incorrect", path, "da39a3ee5e6b4b0d3255bfef95601890afd80709", false, false},
+		{"too short", path, "a9993e36", false, false},
+		{"not hex", path, "not a checksum", false, true},
+		{"missing file", filepath.Join(dir, "missing"), "a9993e364706816aba3e25717850c26c9cd0d89d", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyChecksum(tt.path, tt.expected)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteChecksumFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "abc.txt")
+	if err := os.WriteFile(path, []byte("abc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteChecksumFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path + ".sha1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a9993e364706816aba3e25717850c26c9cd0d89d  abc.txt\n"; string(data) != want {
+		t.Errorf("got %q, want %q", data, want)
+	}
+}
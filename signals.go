@@ -1,28 +1,124 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 )
 
-func main() {
+// ShutdownManager waits for SIGINT/SIGTERM and then runs the registered
+// shutdown handlers, newest first, like deferred calls. SIGHUP only triggers
+// the reload callbacks and keeps the process running.
+type ShutdownManager struct {
+	// Signals receives the signals to act on. Tests can send on it directly
+	// instead of signalling the process.
+	Signals chan os.Signal
+	Timeout time.Duration
+
+	mu       sync.Mutex
+	handlers []func(context.Context) error
+	reloads  []func()
+	exit     func(code int)
+}
+
+func NewShutdownManager(timeout time.Duration) *ShutdownManager {
+	m := &ShutdownManager{
+		Signals: make(chan os.Signal, 1),
+		Timeout: timeout,
+		exit:    os.Exit,
+	}
+	signal.Notify(m.Signals, syscall.SIGINT, syscall.SIGTERM)
+	return m
+}
+
+func (m *ShutdownManager) OnShutdown(fn func(context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers = append(m.handlers, fn)
+}
 
-	sigs := make(chan os.Signal, 1)
-	done := make(chan bool, 1)
+// NotifyReload registers fn to run on every SIGHUP, e.g. to re-read config.
+func (m *ShutdownManager) NotifyReload(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.reloads) == 0 {
+		signal.Notify(m.Signals, syscall.SIGHUP)
+	}
+	m.reloads = append(m.reloads, fn)
+}
 
-	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+// Wait blocks until a terminating signal arrives, then runs the handlers. If
+// they don't finish within Timeout the process is forced to exit with
+// status 1.
+func (m *ShutdownManager) Wait() os.Signal {
+	sig := <-m.Signals
+	for sig == syscall.SIGHUP {
+		m.reload()
+		sig = <-m.Signals
+	}
+	signal.Stop(m.Signals)
 
+	ctx, cancel := context.WithTimeout(context.Background(), m.Timeout)
+	defer cancel()
+
+	m.mu.Lock()
+	handlers := append([]func(context.Context) error(nil), m.handlers...)
+	m.mu.Unlock()
+
+	done := make(chan struct{})
 	go func() {
-		sig := <-sigs
-		fmt.Println()
-		fmt.Println(sig)
-		done <- true
+		defer close(done)
+		for i := len(handlers) - 1; i >= 0; i-- {
+			if err := handlers[i](ctx); err != nil {
+				fmt.Fprintf(os.Stderr, "shutdown handler failed : %v\n", err)
+			}
+		}
 	}()
 
+	select {
+	case <-done:
+	case <-ctx.Done():
+		fmt.Fprintln(os.Stderr, "shutdown handlers timed out, forcing exit")
+		m.exit(1)
+	}
+	return sig
+}
+
+func (m *ShutdownManager) reload() {
+	m.mu.Lock()
+	reloads := append([]func(){}, m.reloads...)
+	m.mu.Unlock()
+
+	for _, fn := range reloads {
+		fn()
+	}
+}
+
+func main() {
+
+	manager := NewShutdownManager(5 * time.Second)
+
+	manager.OnShutdown(func(ctx context.Context) error {
+		fmt.Println("closing connections")
+		return nil
+	})
+	manager.OnShutdown(func(ctx context.Context) error {
+		fmt.Println("flushing buffers")
+		return nil
+	})
+
+	manager.NotifyReload(func() {
+		fmt.Println("reloading config")
+	})
+
 	fmt.Println("awaiting signal")
-	<-done
+	sig := manager.Wait()
+	fmt.Println()
+	fmt.Println(sig)
 	fmt.Println("exiting")
 }
 
@@ -33,4 +129,24 @@ func main() {
 	interrupt
 	exiting
 	raja@raja-Latitude-3460:~/Documents/coding/golang/go-by-examples$
+
+	With the ShutdownManager the handlers run newest first before Wait returns:
+	$ go run signals.go
+	awaiting signal
+	^Cflushing buffers
+	closing connections
+
+	interrupt
+	exiting
+
+	SIGHUP (kill -HUP <pid>) reloads without exiting:
+	$ go run signals.go
+	awaiting signal
+	reloading config
+	reloading config
+	^Cflushing buffers
+	closing connections
+
+	interrupt
+	exiting
 */
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestShutdownManagerRunsHandlersNewestFirst(t *testing.T) {
+	m := NewShutdownManager(time.Second)
+	m.exit = func(code int) { t.Errorf("exit(%d) called, want handlers to finish", code) }
+
+	var order []string
+	m.OnShutdown(func(ctx context.Context) error { order = append(order, "first"); return nil })
+	m.OnShutdown(func(ctx context.Context) error { order = append(order, "second"); return nil })
+	m.OnShutdown(func(ctx context.Context) error { order = append(order, "third"); return nil })
+
+	m.Signals <- syscall.SIGTERM
+	if sig := m.Wait(); sig != syscall.SIGTERM {
+		t.Errorf("Wait returned %v, want SIGTERM", sig)
+	}
+	if want := []string{"third", "second", "first"}; !slices.Equal(order, want) {
+		t.Errorf("handlers ran in order %v, want %v", order, want)
+	}
+}
+
+func TestShutdownManagerForcesExitOnTimeout(t *testing.T) {
+	m := NewShutdownManager(50 * time.Millisecond)
+	exitCode := -1
+	m.exit = func(code int) { exitCode = code }
+
+	release := make(chan struct{})
+	defer close(release)
+	m.OnShutdown(func(ctx context.Context) error {
+		// ignores ctx, like a handler stuck on a hung connection
+		<-release
+		return nil
+	})
+
+	m.Signals <- syscall.SIGINT
+	start := time.Now()
+	m.Wait()
+
+	if exitCode != 1 {
+		t.Errorf("got exit code %d, want 1", exitCode)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("forced exit came after %v, want about the 50ms timeout", elapsed)
+	}
+}
+
+func TestShutdownManagerReloadsOnSIGHUP(t *testing.T) {
+	m := NewShutdownManager(time.Second)
+	reloaded := make(chan struct{})
+	m.NotifyReload(func() { reloaded <- struct{}{} })
+
+	returned := make(chan struct{})
+	go func() {
+		defer close(returned)
+		m.Wait()
+	}()
+
+	for i := 0; i < 3; i++ {
+		m.Signals <- syscall.SIGHUP
+		select {
+		case <-reloaded:
+		case <-time.After(time.Second):
+			t.Fatalf("SIGHUP %d did not reload", i+1)
+		}
+		select {
+		case <-returned:
+			t.Fatalf("Wait returned after SIGHUP %d", i+1)
+		default:
+		}
+	}
+
+	m.Signals <- syscall.SIGTERM
+	select {
+	case <-returned:
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return after SIGTERM")
+	}
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
 	"sort"
 )
 
@@ -17,8 +19,48 @@ func (s byLength) Less(i, j int) bool {
 	return len(s[i]) > len(s[j])
 }
 
+type Person struct {
+	Name string
+	Age  int
+}
+
+func ByAge(a, b Person) int  { return cmp.Compare(a.Age, b.Age) }
+func ByName(a, b Person) int { return cmp.Compare(a.Name, b.Name) }
+
+// SortBy sorts people by the first comparator, breaking ties with the next
+// one and so on. People equal under all of them keep their original order.
+func SortBy(people []Person, cmps ...func(a, b Person) int) {
+	slices.SortStableFunc(people, func(a, b Person) int {
+		for _, c := range cmps {
+			if r := c(a, b); r != 0 {
+				return r
+			}
+		}
+		return 0
+	})
+}
+
 func main() {
 	fruits := []string{"peach", "banana", "kiwi"}
 	sort.Sort(byLength(fruits))
 	fmt.Println(fruits)
+
+	people := []Person{
+		{"Jax", 37},
+		{"Alex", 72},
+		{"TJ", 25},
+		{"Alex", 25},
+		{"Bo", 37},
+	}
+	SortBy(people, ByAge, ByName)
+	fmt.Println(people)
+	SortBy(people, ByName)
+	fmt.Println(people)
 }
+
+/*
+	$ go run sorting-by-functions.go
+	[banana peach kiwi]
+	[{Alex 25} {TJ 25} {Bo 37} {Jax 37} {Alex 72}]
+	[{Alex 25} {Alex 72} {Bo 37} {Jax 37} {TJ 25}]
+*/
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestSortByTieBreak(t *testing.T) {
+	people := []Person{{"Bob", 30}, {"alice", 25}, {"Carol", 30}, {"Alice", 30}, {"Dave", 25}}
+	SortBy(people, ByAge, ByName)
+
+	want := []Person{{"Dave", 25}, {"alice", 25}, {"Alice", 30}, {"Bob", 30}, {"Carol", 30}}
+	if !slices.Equal(people, want) {
+		t.Errorf("got %v, want %v", people, want)
+	}
+}
+
+func TestSortByStable(t *testing.T) {
+	// enough equal ages that an unstable sort would reorder some of them
+	var people []Person
+	for i := 0; i < 100; i++ {
+		people = append(people, Person{Name: fmt.Sprintf("p%02d", i), Age: i % 3})
+	}
+	SortBy(people, ByAge)
+
+	for i := 1; i < len(people); i++ {
+		prev, cur := people[i-1], people[i]
+		if prev.Age > cur.Age || prev.Age == cur.Age && prev.Name > cur.Name {
+			t.Fatalf("got %v before %v, want ties in their original order", prev, cur)
+		}
+	}
+}
+
+func TestSortByNoComparators(t *testing.T) {
+	people := []Person{{"Bob", 30}, {"Alice", 25}}
+	SortBy(people)
+	if want := []Person{{"Bob", 30}, {"Alice", 25}}; !slices.Equal(people, want) {
+		t.Errorf("got %v, want %v unchanged", people, want)
+	}
+}
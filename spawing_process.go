@@ -1,11 +1,172 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"os"
 	"os/exec"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
 )
 
+// RunCommandContext, RunWithTimeout and StartGroup kill whole process groups
+// on Unix, so they live in spawing_process_unix.go and
+// spawing_process_windows.go. Run this example with:
+// go run spawing_process.go spawing_process_unix.go
+// (or spawing_process_windows.go on Windows).
+
+// RunCommand runs name with args and captures stdout and stderr separately.
+// A command that ran but exited non-zero is reported through exitCode, while
+// err is only set when the command could not be run at all.
+func RunCommand(name string, args ...string) (stdout string, stderr string, exitCode int, err error) {
+	var outBuf, errBuf bytes.Buffer
+
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = &outBuf
+	cmd.Stderr = &errBuf
+
+	err = cmd.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return outBuf.String(), errBuf.String(), exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return outBuf.String(), errBuf.String(), -1, err
+	}
+	return outBuf.String(), errBuf.String(), 0, nil
+}
+
+var ErrCommandTimeout = errors.New("command timed out")
+
+// RunInDir runs name in dir with env added to the inherited environment and
+// returns its stdout. A key in env replaces the inherited value: exec.Cmd
+// keeps only the last of duplicate keys, so env goes after os.Environ().
+func RunInDir(dir string, env map[string]string, name string, args ...string) ([]byte, error) {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	for _, k := range keys {
+		cmd.Env = append(cmd.Env, k+"="+env[k])
+	}
+	return cmd.Output()
+}
+
+// StreamCommand feeds stdin to the command and calls onLine for every line of
+// its output as soon as it's printed, instead of waiting for the command to
+// exit. Lines of any length come through whole. It returns the error from
+// Wait.
+func StreamCommand(name string, args []string, stdin io.Reader, onLine func(string)) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = stdin
+
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	var readErr error
+	br := bufio.NewReader(out)
+	for {
+		line, err := br.ReadString('\n')
+		if len(line) > 0 {
+			onLine(strings.TrimSuffix(line, "\n"))
+		}
+		if err != nil {
+			if err != io.EOF {
+				readErr = err
+			}
+			break
+		}
+	}
+	// Wait must not run with output left unread, or a command blocked on a
+	// full pipe never exits
+	io.Copy(io.Discard, out)
+
+	if err := cmd.Wait(); err != nil {
+		return err
+	}
+	return readErr
+}
+
+// Pipeline wires commands together like a shell pipeline, each stdout feeding
+// the next stdin, and returns the output of the last command. The first
+// command that fails decides the returned error. As in a shell, a command
+// killed by SIGPIPE because a later one exited early, like yes in
+// yes | head -1, doesn't count as failing.
+func Pipeline(commands ...*exec.Cmd) ([]byte, error) {
+	if len(commands) == 0 {
+		return nil, errors.New("pipeline needs at least one command")
+	}
+
+	// our copies of the pipe ends must be closed once the commands have
+	// theirs: a reader only sees EOF when every write end is closed, and a
+	// writer only gets SIGPIPE when every read end is
+	var pipes []*os.File
+	closePipes := func() {
+		for _, p := range pipes {
+			p.Close()
+		}
+	}
+	for i := 0; i < len(commands)-1; i++ {
+		r, w, err := os.Pipe()
+		if err != nil {
+			closePipes()
+			return nil, err
+		}
+		pipes = append(pipes, r, w)
+		commands[i].Stdout = w
+		commands[i+1].Stdin = r
+	}
+
+	var output bytes.Buffer
+	commands[len(commands)-1].Stdout = &output
+
+	for i, cmd := range commands {
+		if err := cmd.Start(); err != nil {
+			closePipes()
+			for _, started := range commands[:i] {
+				started.Process.Kill()
+				started.Wait()
+			}
+			return nil, err
+		}
+	}
+	closePipes()
+
+	var firstErr error
+	for i, cmd := range commands {
+		err := cmd.Wait()
+		if err == nil || firstErr != nil {
+			continue
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && i < len(commands)-1 {
+			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() && status.Signal() == syscall.SIGPIPE {
+				continue
+			}
+		}
+		firstErr = fmt.Errorf("%s : %w", cmd.Path, err)
+	}
+	return output.Bytes(), firstErr
+}
+
 func main() {
 
 	dateCmd := exec.Command("date")
@@ -19,13 +180,26 @@ func main() {
 
 	grepCmd := exec.Command("grep", "hello")
 
-	grepIn, _ := grepCmd.StdinPipe()
-	grepOut, _ := grepCmd.StdoutPipe()
-	grepCmd.Start()
+	grepIn, err := grepCmd.StdinPipe()
+	if err != nil {
+		panic(err)
+	}
+	grepOut, err := grepCmd.StdoutPipe()
+	if err != nil {
+		panic(err)
+	}
+	if err := grepCmd.Start(); err != nil {
+		panic(err)
+	}
 	grepIn.Write([]byte("hello grep\ngoodbye grep"))
 	grepIn.Close()
-	grepBytes, _ := ioutil.ReadAll(grepOut)
-	grepCmd.Wait()
+	grepBytes, err := ioutil.ReadAll(grepOut)
+	if err != nil {
+		panic(err)
+	}
+	if err := grepCmd.Wait(); err != nil {
+		panic(err)
+	}
 
 	fmt.Println("> grep hello")
 	fmt.Println(string(grepBytes))
@@ -37,10 +211,66 @@ func main() {
 	}
 	fmt.Println("> ls -a -l -h")
 	fmt.Println(string(lsOut))
+
+	stdout, stderr, code, err := RunCommand("bash", "-c", "echo to stdout; echo to stderr >&2; exit 3")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("> bash -c 'echo to stdout; echo to stderr >&2; exit 3'")
+	fmt.Printf("stdout: %q, stderr: %q, exit code: %d\n", stdout, stderr, code)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = RunCommandContext(ctx, "bash", "-c", "sleep 10 & sleep 10")
+	fmt.Println("> bash -c 'sleep 10 & sleep 10' (100ms timeout)")
+	fmt.Println(err)
+
+	for _, script := range []string{"echo ok; echo warn >&2", "echo failing >&2; exit 1", "echo started; sleep 10"} {
+		out, err := RunWithTimeout(500*time.Millisecond, "bash", "-c", script)
+		fmt.Printf("> bash -c '%s' (500ms timeout)\n", script)
+		fmt.Printf("output: %q, error: %v\n", out, err)
+	}
+
+	dirOut, err := RunInDir("/tmp", map[string]string{"GREETING": "hello", "HOME": "/nowhere"}, "bash", "-c", "pwd; echo $GREETING $HOME")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("> bash -c 'pwd; echo $GREETING $HOME' (in /tmp, GREETING=hello HOME=/nowhere)")
+	fmt.Print(string(dirOut))
+
+	groupCmd, kill, err := StartGroup("bash", "-c", "sleep 100 & sleep 100")
+	if err != nil {
+		panic(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	fmt.Println("> bash -c 'sleep 100 & sleep 100' (killed as a group)")
+	fmt.Println(kill(), groupCmd.Wait())
+
+	fmt.Println("> while read line; do echo \"got $line\"; sleep 0.1; done")
+	start := time.Now()
+	err = StreamCommand("bash", []string{"-c", `while read line; do echo "got $line"; sleep 0.1; done`},
+		bytes.NewBufferString("one\ntwo\nthree\n"),
+		func(line string) {
+			fmt.Printf("[%v] %s\n", time.Since(start).Round(100*time.Millisecond), line)
+		})
+	if err != nil {
+		panic(err)
+	}
+
+	pipeOut, err := Pipeline(
+		exec.Command("echo", "a\nb\na"),
+		exec.Command("grep", "a"),
+		exec.Command("wc", "-l"),
+	)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("> echo \"a\\nb\\na\" | grep a | wc -l")
+	fmt.Println(string(pipeOut))
 }
 
 /*
-raja@raja-Latitude-3460:~/Documents/coding/golang/go-by-examples$ go run spawing_process.go
+raja@raja-Latitude-3460:~/Documents/coding/golang/go-by-examples$ go run spawing_process.go spawing_process_unix.go
 > date
 Mon  7 Jun 10:43:20 +08 2021
 
@@ -63,4 +293,27 @@ drwxrwxr-x 8 raja raja 4.0K Jun  7 10:28 .git
 -rw-rw-r-- 1 raja raja 1.4K Jun  6 10:38 panic.go
 -rw-rw-r-- 1 raja raja  11K Jun  7 10:42 README.md
 -rw-rw-r-- 1 raja raja  718 Jun  7 10:42 spawing_process.go
+
+> bash -c 'echo to stdout; echo to stderr >&2; exit 3'
+stdout: "to stdout\n", stderr: "to stderr\n", exit code: 3
+> bash -c 'sleep 10 & sleep 10' (100ms timeout)
+bash [-c sleep 10 & sleep 10] : context deadline exceeded
+> bash -c 'echo ok; echo warn >&2' (500ms timeout)
+output: "ok\nwarn\n", error: <nil>
+> bash -c 'echo failing >&2; exit 1' (500ms timeout)
+output: "failing\n", error: exit status 1
+> bash -c 'echo started; sleep 10' (500ms timeout)
+output: "started\n", error: bash [-c echo started; sleep 10] : command timed out after 500ms
+> bash -c 'pwd; echo $GREETING $HOME' (in /tmp, GREETING=hello HOME=/nowhere)
+/tmp
+hello /nowhere
+> bash -c 'sleep 100 & sleep 100' (killed as a group)
+<nil> signal: killed
+> while read line; do echo "got $line"; sleep 0.1; done
+[0s] got one
+[100ms] got two
+[200ms] got three
+> echo "a\nb\na" | grep a | wc -l
+2
+
 */
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunCommand(t *testing.T) {
+	var tests = []struct {
+		name       string
+		script     string
+		wantStdout string
+		wantStderr string
+		wantCode   int
+	}{
+		{"stdout only", "echo out", "out\n", "", 0},
+		{"stderr only", "echo err >&2", "", "err\n", 0},
+		{"non-zero exit", "echo out; echo err >&2; exit 3", "out\n", "err\n", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code, err := RunCommand("bash", "-c", tt.script)
+			if err != nil {
+				t.Fatalf("RunCommand failed : %v", err)
+			}
+			if stdout != tt.wantStdout || stderr != tt.wantStderr || code != tt.wantCode {
+				t.Errorf("got %q, %q, %d, want %q, %q, %d", stdout, stderr, code, tt.wantStdout, tt.wantStderr, tt.wantCode)
+			}
+		})
+	}
+
+	t.Run("missing binary", func(t *testing.T) {
+		if _, _, code, err := RunCommand("no-such-command-here"); err == nil || code != -1 {
+			t.Errorf("got code %d, error %v, want -1 and an error", code, err)
+		}
+	})
+}
+
+func TestStreamCommand(t *testing.T) {
+	t.Run("lines arrive as printed", func(t *testing.T) {
+		var lines []string
+		var gaps []time.Duration
+		last := time.Now()
+		err := StreamCommand("bash", []string{"-c", `while read line; do echo "got $line"; sleep 0.1; done`},
+			strings.NewReader("one\ntwo\nthree\n"),
+			func(line string) {
+				gaps = append(gaps, time.Since(last))
+				last = time.Now()
+				lines = append(lines, line)
+			})
+		if err != nil {
+			t.Fatalf("StreamCommand failed : %v", err)
+		}
+		if want := []string{"got one", "got two", "got three"}; !slices.Equal(lines, want) {
+			t.Fatalf("got %q, want %q", lines, want)
+		}
+		// a buffered result would hand over all lines at once at the end
+		for i, gap := range gaps[1:] {
+			if gap < 50*time.Millisecond {
+				t.Errorf("line %d came %v after the previous one, want it streamed", i+2, gap)
+			}
+		}
+	})
+
+	t.Run("line over 64K", func(t *testing.T) {
+		var lengths []int
+		err := StreamCommand("bash", []string{"-c", "head -c 100000 /dev/zero | tr '\\0' x; echo; echo end"}, nil,
+			func(line string) { lengths = append(lengths, len(line)) })
+		if err != nil {
+			t.Fatalf("StreamCommand failed : %v", err)
+		}
+		if !slices.Equal(lengths, []int{100000, 3}) {
+			t.Errorf("got line lengths %v, want [100000 3]", lengths)
+		}
+	})
+}
+
+func TestPipeline(t *testing.T) {
+	var tests = []struct {
+		name     string
+		commands []*exec.Cmd
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "echo | grep a | wc -l",
+			commands: []*exec.Cmd{exec.Command("echo", "a\nb\na"), exec.Command("grep", "a"), exec.Command("wc", "-l")},
+			want:     "2",
+		},
+		{
+			name:     "yes | head -1 exits early",
+			commands: []*exec.Cmd{exec.Command("yes"), exec.Command("head", "-1")},
+			want:     "y",
+		},
+		{
+			name:     "failing command",
+			commands: []*exec.Cmd{exec.Command("echo", "a"), exec.Command("grep", "b")},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := make(chan struct{})
+			var out []byte
+			var err error
+			go func() {
+				defer close(done)
+				out, err = Pipeline(tt.commands...)
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				for _, cmd := range tt.commands {
+					if cmd.Process != nil {
+						cmd.Process.Kill()
+					}
+				}
+				t.Fatal("pipeline did not finish")
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Pipeline error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := strings.TrimSpace(string(out)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunInDir(t *testing.T) {
+	t.Run("pwd", func(t *testing.T) {
+		dir := t.TempDir()
+		out, err := RunInDir(dir, nil, "pwd")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the temp dir may be behind a symlink, e.g. /var on macOS
+		want, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := filepath.EvalSymlinks(strings.TrimSpace(string(out)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("printenv", func(t *testing.T) {
+		t.Setenv("GO_BY_EXAMPLE_INHERITED", "parent")
+		t.Setenv("GO_BY_EXAMPLE_OVERRIDDEN", "parent")
+		env := map[string]string{"GO_BY_EXAMPLE_OVERRIDDEN": "child", "GO_BY_EXAMPLE_NEW": "added"}
+
+		var tests = []struct{ key, want string }{
+			{"GO_BY_EXAMPLE_INHERITED", "parent"},
+			{"GO_BY_EXAMPLE_OVERRIDDEN", "child"},
+			{"GO_BY_EXAMPLE_NEW", "added"},
+		}
+		for _, tt := range tests {
+			out, err := RunInDir(t.TempDir(), env, "printenv", tt.key)
+			if err != nil {
+				t.Fatalf("printenv %s : %v", tt.key, err)
+			}
+			if got := strings.TrimSpace(string(out)); got != tt.want {
+				t.Errorf("%s: got %q, want %q", tt.key, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("missing dir", func(t *testing.T) {
+		if _, err := RunInDir(filepath.Join(t.TempDir(), "missing"), nil, "pwd"); err == nil {
+			t.Error("got nil error, want one for a missing directory")
+		}
+	})
+}
+
+// doneAfterwards is a context that reports being cancelled without ever
+// closing Done, as if it was cancelled just after the command exited.
+type doneAfterwards struct {
+	context.Context
+}
+
+func (doneAfterwards) Err() error { return context.Canceled }
+
+func TestRunCommandContextFinishedBeforeCancel(t *testing.T) {
+	out, err := RunCommandContext(doneAfterwards{context.Background()}, "echo", "done")
+	if err != nil {
+		t.Errorf("got %v, want nil for a command that completed", err)
+	}
+	if string(out) != "done\n" {
+		t.Errorf("got %q, want %q", out, "done\n")
+	}
+}
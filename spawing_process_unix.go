@@ -0,0 +1,75 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// RunCommandContext runs name in its own process group and returns its
+// stdout. When ctx is done the whole group is killed, so grandchildren started
+// by the command don't outlive it.
+func RunCommandContext(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	// a killed group member may still hold our stdout pipe open
+	cmd.WaitDelay = time.Second
+
+	out, err := cmd.Output()
+	// a command that finished before ctx was done succeeded, whatever ctx
+	// says by now
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		return out, fmt.Errorf("%s %v : %w", name, args, ctxErr)
+	}
+	return out, err
+}
+
+// RunWithTimeout runs name and returns its interleaved stdout and stderr. If
+// it hasn't finished within d its process group is killed and the error wraps
+// ErrCommandTimeout; the output printed until then is still returned.
+func RunWithTimeout(d time.Duration, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second
+
+	out, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return string(out), fmt.Errorf("%s %v : %w after %v", name, args, ErrCommandTimeout, d)
+	}
+	return string(out), err
+}
+
+// StartGroup starts name as the leader of a new process group. The returned
+// kill sends SIGKILL to the whole group, so children the command started die
+// with it instead of being orphaned. The caller still Waits for cmd.
+func StartGroup(name string, args ...string) (*exec.Cmd, func() error, error) {
+	cmd := exec.Command(name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+
+	kill := func() error {
+		err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		if errors.Is(err, syscall.ESRCH) {
+			// the group is already gone
+			return nil
+		}
+		return err
+	}
+	return cmd, kill, nil
+}
@@ -0,0 +1,147 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRunCommandContextKillsGroup(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	// the backgrounded sleep keeps stdout open unless the whole group dies
+	_, err := RunCommandContext(ctx, "bash", "-c", "sleep 10 & sleep 10")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %v to return, want about 100ms", elapsed)
+	}
+}
+
+func TestRunWithTimeout(t *testing.T) {
+	t.Run("fast", func(t *testing.T) {
+		out, err := RunWithTimeout(5*time.Second, "sh", "-c", "echo out; echo err >&2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != "out\nerr\n" {
+			t.Errorf("got %q, want stdout and stderr interleaved", out)
+		}
+	})
+
+	t.Run("failing", func(t *testing.T) {
+		out, err := RunWithTimeout(5*time.Second, "sh", "-c", "echo oops >&2; exit 3")
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+			t.Fatalf("got %v, want exit status 3", err)
+		}
+		if errors.Is(err, ErrCommandTimeout) {
+			t.Errorf("got %v, want a failure, not a timeout", err)
+		}
+		if out != "oops\n" {
+			t.Errorf("got %q, want the stderr output", out)
+		}
+	})
+
+	t.Run("slow", func(t *testing.T) {
+		start := time.Now()
+		out, err := RunWithTimeout(200*time.Millisecond, "sh", "-c", "echo started; sleep 10 & sleep 10")
+		if !errors.Is(err, ErrCommandTimeout) {
+			t.Errorf("got %v, want ErrCommandTimeout", err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("took %v to return, want about 200ms", elapsed)
+		}
+		if out != "started\n" {
+			t.Errorf("got %q, want the output from before the timeout", out)
+		}
+	})
+}
+
+func TestStartGroupKillsGrandchildren(t *testing.T) {
+	// the shell leads the group and creates started once its backgrounded
+	// sleep has been forked, so there is a grandchild to kill
+	started := filepath.Join(t.TempDir(), "started")
+	cmd, kill, err := StartGroup("sh", "-c", `sleep 10 & touch "$0"; wait`, started)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pgid := cmd.Process.Pid
+	// don't leave the group behind if the test fails
+	defer syscall.Kill(-pgid, syscall.SIGKILL)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(started); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the grandchild never started")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := kill(); err != nil {
+		t.Fatalf("kill : %v", err)
+	}
+	cmd.Wait()
+
+	// the orphaned sleep is reaped by init, not by us, and until then it is
+	// a zombie: dead, but still a group member as far as kill is concerned
+	deadline = time.Now().Add(5 * time.Second)
+	for {
+		if err := syscall.Kill(-pgid, 0); errors.Is(err, syscall.ESRCH) {
+			break
+		}
+		live, ok := liveGroupMembers(pgid)
+		if ok && len(live) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %v still running in group %d, want the whole group killed", live, pgid)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// killing an empty group is not an error
+	if err := kill(); err != nil {
+		t.Errorf("second kill: got %v, want nil", err)
+	}
+}
+
+// liveGroupMembers returns the pids in group pgid that aren't zombies, read
+// from /proc. ok is false where there is no /proc to read.
+func liveGroupMembers(pgid int) (live []int, ok bool) {
+	stats, err := filepath.Glob("/proc/[0-9]*/stat")
+	if err != nil || len(stats) == 0 {
+		return nil, false
+	}
+
+	for _, path := range stats {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue // exited meanwhile
+		}
+		// pid (comm) state ppid pgrp ..., and comm may contain spaces
+		stat := string(data)
+		fields := strings.Fields(stat[strings.LastIndexByte(stat, ')')+1:])
+		if len(fields) < 3 || fields[2] != strconv.Itoa(pgid) || fields[0] == "Z" {
+			continue
+		}
+		pid, _ := strconv.Atoi(filepath.Base(filepath.Dir(path)))
+		live = append(live, pid)
+	}
+	return live, true
+}
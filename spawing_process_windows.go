@@ -0,0 +1,65 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// RunCommandContext runs name and returns its stdout, killing it once ctx is
+// done. Windows has no process groups to signal, so only the command itself
+// is killed; children it started keep running.
+func RunCommandContext(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	// a surviving child may still hold our stdout pipe open
+	cmd.WaitDelay = time.Second
+
+	out, err := cmd.Output()
+	// a command that finished before ctx was done succeeded, whatever ctx
+	// says by now
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		return out, fmt.Errorf("%s %v : %w", name, args, ctxErr)
+	}
+	return out, err
+}
+
+// RunWithTimeout runs name and returns its interleaved stdout and stderr. If
+// it hasn't finished within d it is killed and the error wraps
+// ErrCommandTimeout; the output printed until then is still returned.
+func RunWithTimeout(d time.Duration, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = time.Second
+
+	out, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return string(out), fmt.Errorf("%s %v : %w after %v", name, args, ErrCommandTimeout, d)
+	}
+	return string(out), err
+}
+
+// StartGroup starts name and returns a kill for it. Unlike on Unix there is
+// no group to kill, so only the command itself dies. The caller still Waits
+// for cmd.
+func StartGroup(name string, args ...string) (*exec.Cmd, func() error, error) {
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+
+	kill := func() error {
+		err := cmd.Process.Kill()
+		if errors.Is(err, os.ErrProcessDone) {
+			return nil
+		}
+		return err
+	}
+	return cmd, kill, nil
+}
@@ -3,12 +3,64 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
+	"unicode/utf8"
 )
 
 type point struct {
 	x, y int
 }
 
+// FormatTable renders headers and rows as left-aligned columns, each as wide
+// as its widest cell and two spaces apart, with a dashed line under the
+// headers. Short rows are padded with blank cells.
+func FormatTable(headers []string, rows [][]string) string {
+	cols := len(headers)
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+
+	widths := make([]int, cols)
+	measure := func(row []string) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	measure(headers)
+	for _, row := range rows {
+		measure(row)
+	}
+
+	var b strings.Builder
+	writeRow := func(row []string) {
+		var line strings.Builder
+		for i, width := range widths {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			if i > 0 {
+				line.WriteString("  ")
+			}
+			// %-*s pads to width runes
+			fmt.Fprintf(&line, "%-*s", width, cell)
+		}
+		b.WriteString(strings.TrimRight(line.String(), " "))
+		b.WriteByte('\n')
+	}
+
+	writeRow(headers)
+	dashes := make([]string, cols)
+	for i, width := range widths {
+		dashes[i] = strings.Repeat("-", width)
+	}
+	writeRow(dashes)
+	for _, row := range rows {
+		writeRow(row)
+	}
+	return b.String()
+}
+
 func main() {
 
 	p := point{1, 2}
@@ -57,4 +109,44 @@ func main() {
 	fmt.Println(s)
 
 	fmt.Fprintf(os.Stderr, "an %s\n", "error")
+
+	fmt.Print(FormatTable(
+		[]string{"NAME", "AGE", "CITY"},
+		[][]string{
+			{"Alice", "30", "Zürich"},
+			{"Bob", "4"},
+			{"Christopher", "101", "Rome"},
+		}))
 }
+
+/*
+	$ go run string_formatting.go
+	{1 2}
+	{x:1 y:2}
+	main.point{x:1, y:2}
+	main.point
+	true
+	123
+	1110
+	!
+	1c8
+	78.900000
+	1.234000e+08
+	1.234000E+08
+	"string"
+	"\"string\""
+	6865782074686973
+	0x1c2a4ccaa160
+	|    12|   345|
+	|  1.20|  3.45|
+	|1.20  |3.45  |
+	|   foo|     b|
+	|foo   |b     |
+	a string
+	an error
+	NAME         AGE  CITY
+	-----------  ---  ------
+	Alice        30   Zürich
+	Bob          4
+	Christopher  101  Rome
+*/
@@ -0,0 +1,62 @@
+package main
+
+import "testing"
+
+func TestFormatTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		rows    [][]string
+		want    string
+	}{
+		{
+			"aligned",
+			[]string{"NAME", "AGE"},
+			[][]string{{"Alice", "30"}, {"Bob", "7"}},
+			"NAME   AGE\n" +
+				"-----  ---\n" +
+				"Alice  30\n" +
+				"Bob    7\n",
+		},
+		{
+			"short row padded",
+			[]string{"A", "B", "C"},
+			[][]string{{"1"}, {"1", "22", "333"}},
+			"A  B   C\n" +
+				"-  --  ---\n" +
+				"1\n" +
+				"1  22  333\n",
+		},
+		{
+			"row wider than headers",
+			[]string{"K"},
+			[][]string{{"k", "extra"}},
+			"K\n" +
+				"-  -----\n" +
+				"k  extra\n",
+		},
+		{
+			"multibyte counted as one",
+			[]string{"CITY", "N"},
+			[][]string{{"Zürich", "1"}, {"Oslo", "2"}},
+			"CITY    N\n" +
+				"------  -\n" +
+				"Zürich  1\n" +
+				"Oslo    2\n",
+		},
+		{
+			"no rows",
+			[]string{"ID"},
+			nil,
+			"ID\n" +
+				"--\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatTable(tt.headers, tt.rows); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
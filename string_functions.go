@@ -3,10 +3,60 @@ package main
 import (
 	"fmt"
 	s "strings"
+	"unicode"
 )
 
 var p = fmt.Println
 
+// clusters splits str into runes with any combining marks that follow them,
+// so an accent stays on its letter. It doesn't know about longer grapheme
+// clusters such as emoji joined with ZWJ.
+func clusters(str string) []string {
+	var out []string
+	for _, r := range str {
+		if len(out) > 0 && unicode.Is(unicode.Mn, r) {
+			out[len(out)-1] += string(r)
+			continue
+		}
+		out = append(out, string(r))
+	}
+	return out
+}
+
+// Truncate shortens str to at most limit characters, ending in "…" when
+// anything was cut. It never splits a rune or drops a combining mark.
+func Truncate(str string, limit int) string {
+	cs := clusters(str)
+	if len(cs) <= limit {
+		return str
+	}
+	if limit <= 0 {
+		return ""
+	}
+	return s.Join(cs[:limit-1], "") + "…"
+}
+
+// Reverse reverses str by character, not byte, so multibyte runes survive.
+func Reverse(str string) string {
+	cs := clusters(str)
+	for i, j := 0, len(cs)-1; i < j; i, j = i+1, j-1 {
+		cs[i], cs[j] = cs[j], cs[i]
+	}
+	return s.Join(cs, "")
+}
+
+// SplitAndTrim splits str on sep, trims space around each part and drops
+// the parts left empty.
+func SplitAndTrim(str, sep string) []string {
+	var parts []string
+	for _, part := range s.Split(str, sep) {
+		if part = s.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
 func main() {
 
 	p("Contains:  ", s.Contains("test", "es"))
@@ -25,4 +75,34 @@ func main() {
 
 	p("Len: ", len("hello"))
 	p("Char:", "hello"[1])
+	p()
+
+	p("Truncate:    ", Truncate("héllo, wörld", 6))
+	p("Truncate:    ", Truncate("go 🚀🚀🚀", 5))
+	p("Reverse:     ", Reverse("noe\u0308l 🚀"))
+	p("SplitAndTrim:", s.Join(SplitAndTrim(" a, b ,,c ", ","), "|"))
 }
+
+/*
+	$ go run string_functions.go
+	Contains:   true
+	Count:      2
+	HasPrefix:  true
+	HasSuffix:  true
+	Index:      1
+	Join:       a-b
+	Repeat:     aaaaa
+	Replace:    f00
+	Replace:    f0o
+	Split:      [a b c d e]
+	ToLower:    test
+	ToUpper:    TEST
+
+	Len:  5
+	Char: 101
+
+	Truncate:     héllo…
+	Truncate:     go 🚀…
+	Reverse:      🚀 lëon
+	SplitAndTrim: a|b|c
+*/
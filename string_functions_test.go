@@ -0,0 +1,76 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// "e\u0301" is e followed by a combining acute accent, two runes that show
+// as one character.
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		limit int
+		want  string
+	}{
+		{"ascii fits", "hello", 5, "hello"},
+		{"ascii cut", "hello world", 5, "hell…"},
+		{"zero limit", "hello", 0, ""},
+		{"empty", "", 3, ""},
+		{"emoji", "🍎🍐🍑🍒", 3, "🍎🍐…"},
+		{"combining fits", "cafe\u0301", 4, "cafe\u0301"},
+		{"combining cut", "cafe\u0301s", 4, "caf…"},
+		{"combining kept", "e\u0301e\u0301e\u0301", 2, "e\u0301…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Truncate(tt.in, tt.limit); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReverse(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"ascii", "hello", "olleh"},
+		{"empty", "", ""},
+		{"emoji", "a🍎b🍐", "🍐b🍎a"},
+		{"combining", "cafe\u0301", "e\u0301fac"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Reverse(tt.in); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if back := Reverse(Reverse(tt.in)); back != tt.in {
+				t.Errorf("reversed twice: got %q, want %q", back, tt.in)
+			}
+		})
+	}
+}
+
+func TestSplitAndTrim(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"ascii", " a, b ,c ", []string{"a", "b", "c"}},
+		{"empty parts dropped", "a,, ,b,", []string{"a", "b"}},
+		{"only separators", ", ,", nil},
+		{"emoji and combining", " 🍎 ,cafe\u0301 ", []string{"🍎", "cafe\u0301"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitAndTrim(tt.in, ","); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -13,6 +14,42 @@ func check(e error) {
 	}
 }
 
+// WithTempDir creates a temp directory, runs fn with it and removes the
+// directory afterwards, even if fn panics. The panic is re-raised once the
+// cleanup is done.
+func WithTempDir(fn func(dir string) error) (err error) {
+	dir, err := os.MkdirTemp("", "withtempdir")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		r := recover()
+		err = errors.Join(err, os.RemoveAll(dir))
+		if r != nil {
+			panic(r)
+		}
+	}()
+	return fn(dir)
+}
+
+// WithTempFile is WithTempDir for a single file, which is closed and removed
+// after fn.
+func WithTempFile(fn func(f *os.File) error) (err error) {
+	f, err := os.CreateTemp("", "withtempfile")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		r := recover()
+		f.Close() // fn may have closed it already
+		err = errors.Join(err, os.Remove(f.Name()))
+		if r != nil {
+			panic(r)
+		}
+	}()
+	return fn(f)
+}
+
 func main() {
 
 	f, err := ioutil.TempFile("", "sample")
@@ -34,4 +71,33 @@ func main() {
 	fname := filepath.Join(dname, "file1")
 	err = ioutil.WriteFile(fname, []byte{1, 2}, 0666)
 	check(err)
+
+	var kept string
+	err = WithTempDir(func(dir string) error {
+		kept = dir
+		return os.WriteFile(filepath.Join(dir, "scratch"), []byte("data"), 0644)
+	})
+	_, statErr := os.Stat(kept)
+	fmt.Println("WithTempDir:", err, os.IsNotExist(statErr))
+
+	func() {
+		defer func() {
+			fmt.Println("recovered:", recover())
+		}()
+		WithTempFile(func(f *os.File) error {
+			kept = f.Name()
+			panic("boom")
+		})
+	}()
+	_, statErr = os.Stat(kept)
+	fmt.Println("WithTempFile removed after panic:", os.IsNotExist(statErr))
 }
+
+/*
+	$ go run temporary-files-and-directories.go
+	Temp file name: /tmp/sample3453731511
+	Temp dir name: /tmp/sampledir2760932751
+	WithTempDir: <nil> true
+	recovered: boom
+	WithTempFile removed after panic: true
+*/
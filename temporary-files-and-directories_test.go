@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithTempDir(t *testing.T) {
+	var seen string
+	err := WithTempDir(func(dir string) error {
+		seen = dir
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			t.Errorf("during: got %v, want %s to be a directory", err, dir)
+		}
+		return os.WriteFile(filepath.Join(dir, "data"), []byte("x"), 0644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(seen); !os.IsNotExist(err) {
+		t.Errorf("after: got %v, want %s removed along with its contents", err, seen)
+	}
+}
+
+func TestWithTempFile(t *testing.T) {
+	var seen string
+	err := WithTempFile(func(f *os.File) error {
+		seen = f.Name()
+		if _, err := os.Stat(seen); err != nil {
+			t.Errorf("during: got %v, want %s to exist", err, seen)
+		}
+		_, err := f.WriteString("x")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(seen); !os.IsNotExist(err) {
+		t.Errorf("after: got %v, want %s removed", err, seen)
+	}
+}
+
+func TestWithTempErrorReturned(t *testing.T) {
+	errFn := errors.New("fn failed")
+	if err := WithTempDir(func(string) error { return errFn }); !errors.Is(err, errFn) {
+		t.Errorf("WithTempDir: got %v, want %v", err, errFn)
+	}
+	if err := WithTempFile(func(*os.File) error { return errFn }); !errors.Is(err, errFn) {
+		t.Errorf("WithTempFile: got %v, want %v", err, errFn)
+	}
+}
+
+func TestWithTempPanic(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(seen *string)
+	}{
+		{"dir", func(seen *string) {
+			WithTempDir(func(dir string) error {
+				*seen = dir
+				panic("boom")
+			})
+		}},
+		{"file", func(seen *string) {
+			WithTempFile(func(f *os.File) error {
+				*seen = f.Name()
+				panic("boom")
+			})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			func() {
+				defer func() {
+					if r := recover(); r != "boom" {
+						t.Errorf("got panic %v, want boom re-raised", r)
+					}
+				}()
+				tt.run(&seen)
+			}()
+			if _, err := os.Stat(seen); !os.IsNotExist(err) {
+				t.Errorf("got %v, want %s removed despite the panic", err, seen)
+			}
+		})
+	}
+}
@@ -1,10 +1,72 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"time"
 )
 
+// Clock is the part of the time package that Every depends on, so tests can
+// use the FakeClock in fakeclock_test.go and move time by hand instead of
+// sleeping.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type RealClock struct{}
+
+func (RealClock) Now() time.Time                         { return time.Now() }
+func (RealClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+type everyConfig struct {
+	immediately bool
+	clock       Clock
+}
+
+type EveryOption func(*everyConfig)
+
+// Immediately makes Every call fn once before waiting for the first tick.
+func Immediately() EveryOption {
+	return func(c *everyConfig) { c.immediately = true }
+}
+
+// WithClock makes Every wait on clock instead of the real time.
+func WithClock(clock Clock) EveryOption {
+	return func(c *everyConfig) { c.clock = clock }
+}
+
+// Every calls fn every d until ctx is cancelled, and blocks until then. fn
+// runs on the calling goroutine, so once Every returns it is never called
+// again. A tick that races with cancellation is dropped. The next tick is
+// scheduled before fn runs, so a slow fn doesn't push the ticks back, but
+// unlike a time.Ticker a tick missed while fn runs is only delayed, not
+// dropped.
+func Every(ctx context.Context, d time.Duration, fn func(), opts ...EveryOption) {
+	cfg := everyConfig{clock: RealClock{}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.immediately && ctx.Err() == nil {
+		fn()
+	}
+
+	tick := cfg.clock.After(d)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			if ctx.Err() != nil {
+				return
+			}
+			tick = cfg.clock.After(d)
+			fn()
+		}
+	}
+}
+
 func main() {
 
 	ticker := time.NewTicker(500 * time.Millisecond)
@@ -25,4 +87,24 @@ func main() {
 	ticker.Stop()
 	done <- true
 	fmt.Println("Ticker stopped")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	Every(ctx, 500*time.Millisecond, func() {
+		fmt.Println("Every at", time.Since(start).Round(100*time.Millisecond))
+	}, Immediately())
+	fmt.Println("Every stopped")
 }
+
+/*
+	$ go run tickers.go
+	Tick at 2026-10-14 17:21:40.894421222 +0000 UTC m=+0.500040308
+	Tick at 2026-10-14 17:21:41.394420952 +0000 UTC m=+1.000040057
+	Tick at 2026-10-14 17:21:41.894422062 +0000 UTC m=+1.500041260
+	Ticker stopped
+	Every at 0s
+	Every at 500ms
+	Every at 1s
+	Every stopped
+*/
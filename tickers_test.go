@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEveryCountsTicks(t *testing.T) {
+	AssertNoLeaks(t)
+
+	clock := NewFakeClock(time.Date(2021, time.June, 8, 12, 0, 0, 0, time.UTC))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	ticked := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		Every(ctx, time.Minute, func() {
+			calls++
+			ticked <- struct{}{}
+		}, WithClock(clock), Immediately())
+	}()
+
+	<-ticked
+	for i := 0; i < 3; i++ {
+		clock.BlockUntil(1)
+		// half a tick is not enough
+		clock.Advance(30 * time.Second)
+		select {
+		case <-ticked:
+			t.Fatalf("fn ran after half a tick")
+		case <-time.After(10 * time.Millisecond):
+		}
+		clock.Advance(30 * time.Second)
+		<-ticked
+	}
+
+	cancel()
+	<-stopped
+	if calls != 4 {
+		t.Errorf("fn ran %d times, want 4", calls)
+	}
+}
+
+func TestEveryCancelledBeforeStart(t *testing.T) {
+	AssertNoLeaks(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	Every(ctx, time.Minute, func() { calls++ }, WithClock(NewFakeClock(time.Now())), Immediately())
+	if calls != 0 {
+		t.Errorf("fn ran %d times on a cancelled context", calls)
+	}
+}
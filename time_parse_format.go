@@ -2,9 +2,49 @@ package main
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
+// FlexibleLayouts are tried in order by ParseFlexible.
+var FlexibleLayouts = []string{
+	time.RFC3339,
+	time.RFC1123,
+	time.ANSIC,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"01/02/2006",
+}
+
+// ParseFlexible parses s with the first of FlexibleLayouts that fits.
+func ParseFlexible(s string) (time.Time, error) {
+	for _, layout := range FlexibleLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q, tried layouts : %s", s, strings.Join(FlexibleLayouts, " | "))
+}
+
+// InZone converts t to the named IANA zone, e.g. "Asia/Kolkata". The zone
+// database takes care of daylight saving, so the offset is the one in effect
+// at t.
+func InZone(t time.Time, zone string) (time.Time, error) {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("loading time zone %q : %w", zone, err)
+	}
+	return t.In(loc), nil
+}
+
+func FormatInZone(t time.Time, zone, layout string) (string, error) {
+	zt, err := InZone(t, zone)
+	if err != nil {
+		return "", err
+	}
+	return zt.Format(layout), nil
+}
+
 func main() {
 	p := fmt.Println
 
@@ -30,6 +70,24 @@ func main() {
 	ansic := "Mon Jan _2 15:04:05 2006"
 	_, e = time.Parse(ansic, "8:41PM")
 	p(e)
+
+	for _, in := range []string{"2012-11-01T22:08:41+00:00", "Thu Nov  1 22:08:41 2012", "2012-11-01", "11/01/2012", "yesterday"} {
+		t3, e := ParseFlexible(in)
+		p(t3, e)
+	}
+
+	// US clocks jump forward at 2021-03-14 07:00 UTC
+	for _, utc := range []time.Time{
+		time.Date(2021, time.March, 14, 6, 30, 0, 0, time.UTC),
+		time.Date(2021, time.March, 14, 7, 30, 0, 0, time.UTC),
+	} {
+		for _, zone := range []string{"America/New_York", "Asia/Kolkata"} {
+			s, e := FormatInZone(utc, zone, time.RFC3339+" MST")
+			p(zone, s, e)
+		}
+	}
+	_, e = InZone(t, "Mars/Olympus_Mons")
+	p(e)
 }
 
 /*
@@ -42,4 +100,14 @@ func main() {
 	0000-01-01 20:41:00 +0000 UTC
 	2021-06-08T11:40:53-00:00
 	parsing time "8:41PM" as "Mon Jan _2 15:04:05 2006": cannot parse "8:41PM" as "Mon"
+	2012-11-01 22:08:41 +0000 UTC <nil>
+	2012-11-01 22:08:41 +0000 UTC <nil>
+	2012-11-01 00:00:00 +0000 UTC <nil>
+	2012-11-01 00:00:00 +0000 UTC <nil>
+	0001-01-01 00:00:00 +0000 UTC cannot parse "yesterday", tried layouts : 2006-01-02T15:04:05Z07:00 | Mon, 02 Jan 2006 15:04:05 MST | Mon Jan _2 15:04:05 2006 | 2006-01-02 15:04:05 | 2006-01-02 | 01/02/2006
+	America/New_York 2021-03-14T01:30:00-05:00 EST <nil>
+	Asia/Kolkata 2021-03-14T12:00:00+05:30 IST <nil>
+	America/New_York 2021-03-14T03:30:00-04:00 EDT <nil>
+	Asia/Kolkata 2021-03-14T13:00:00+05:30 IST <nil>
+	loading time zone "Mars/Olympus_Mons" : unknown time zone Mars/Olympus_Mons
 */
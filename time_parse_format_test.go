@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFlexible(t *testing.T) {
+	want := time.Date(2012, time.November, 1, 22, 8, 41, 0, time.UTC)
+	day := time.Date(2012, time.November, 1, 0, 0, 0, 0, time.UTC)
+
+	var tests = []struct {
+		in   string
+		want time.Time
+	}{
+		{"2012-11-01T22:08:41Z", want},
+		{"Thu, 01 Nov 2012 22:08:41 UTC", want},
+		{"Thu Nov  1 22:08:41 2012", want},
+		{"2012-11-01 22:08:41", want},
+		{"2012-11-01", day},
+		{"11/01/2012", day},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseFlexible(tt.in)
+			if err != nil {
+				t.Fatalf("ParseFlexible failed : %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	_, err := ParseFlexible("yesterday")
+	if err == nil || !strings.Contains(err.Error(), time.RFC3339) {
+		t.Errorf("got error %v, want one listing the layouts tried", err)
+	}
+}
+
+func TestInZoneOffsets(t *testing.T) {
+	// US clocks jump forward at 2021-03-14 07:00 UTC, India has no DST
+	before := time.Date(2021, time.March, 14, 6, 30, 0, 0, time.UTC)
+	after := time.Date(2021, time.March, 14, 7, 30, 0, 0, time.UTC)
+
+	var tests = []struct {
+		zone       string
+		at         time.Time
+		wantOffset int
+		wantName   string
+	}{
+		{"America/New_York", before, -5 * 3600, "EST"},
+		{"America/New_York", after, -4 * 3600, "EDT"},
+		{"Asia/Kolkata", before, 5*3600 + 1800, "IST"},
+		{"Asia/Kolkata", after, 5*3600 + 1800, "IST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.zone+" "+tt.at.Format(time.Kitchen), func(t *testing.T) {
+			zt, err := InZone(tt.at, tt.zone)
+			if err != nil {
+				t.Skipf("time zone database unavailable : %v", err)
+			}
+			name, offset := zt.Zone()
+			if offset != tt.wantOffset || name != tt.wantName {
+				t.Errorf("got %s %d, want %s %d", name, offset, tt.wantName, tt.wantOffset)
+			}
+			if !zt.Equal(tt.at) {
+				t.Errorf("InZone changed the instant : %v != %v", zt, tt.at)
+			}
+		})
+	}
+
+	if _, err := InZone(before, "Mars/Olympus_Mons"); err == nil {
+		t.Error("InZone accepted an unknown zone")
+	}
+}
@@ -1,10 +1,50 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"time"
 )
 
+// WithTimeout runs fn in a goroutine and returns its result, or the zero value
+// and false if it takes longer than d. The result channel is buffered so a
+// late fn can still finish, but a fn that never returns leaks its goroutine;
+// use WithTimeoutContext when fn can watch for cancellation.
+func WithTimeout[T any](d time.Duration, fn func() T) (T, bool) {
+	done := make(chan T, 1)
+	go func() {
+		done <- fn()
+	}()
+
+	select {
+	case v := <-done:
+		return v, true
+	case <-time.After(d):
+		var zero T
+		return zero, false
+	}
+}
+
+// WithTimeoutContext is WithTimeout for a fn that takes a context, which is
+// cancelled on timeout so fn can clean up and return.
+func WithTimeoutContext[T any](ctx context.Context, d time.Duration, fn func(ctx context.Context) T) (T, bool) {
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	done := make(chan T, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	select {
+	case v := <-done:
+		return v, true
+	case <-ctx.Done():
+		var zero T
+		return zero, false
+	}
+}
+
 func main() {
 
 	c1 := make(chan string, 1)
@@ -31,4 +71,37 @@ func main() {
 	case <-time.After(3 * time.Second):
 		fmt.Println("timeout 2")
 	}
+
+	res, ok := WithTimeout(100*time.Millisecond, func() string {
+		return "result 3"
+	})
+	fmt.Println(res, ok)
+
+	res, ok = WithTimeout(100*time.Millisecond, func() string {
+		time.Sleep(time.Second)
+		return "result 4"
+	})
+	fmt.Printf("%q %v\n", res, ok)
+
+	n, ok := WithTimeoutContext(context.Background(), 100*time.Millisecond, func(ctx context.Context) int {
+		select {
+		case <-time.After(time.Second):
+			return 5
+		case <-ctx.Done():
+			fmt.Println("worker stopped:", ctx.Err())
+			return 0
+		}
+	})
+	fmt.Println(n, ok)
+	time.Sleep(10 * time.Millisecond) // let the worker print before exiting
 }
+
+/*
+	$ go run timeout.go
+	timeout 1
+	result 2
+	result 3 true
+	"" false
+	0 false
+	worker stopped: context deadline exceeded
+*/
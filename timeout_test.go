@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithTimeout(t *testing.T) {
+	AssertNoLeaks(t)
+
+	t.Run("fast", func(t *testing.T) {
+		v, ok := WithTimeout(time.Second, func() string { return "done" })
+		if !ok || v != "done" {
+			t.Errorf("got %q, %v, want done, true", v, ok)
+		}
+	})
+
+	t.Run("slow", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		v, ok := WithTimeout(20*time.Millisecond, func() string {
+			<-release
+			return "late"
+		})
+		if ok || v != "" {
+			t.Errorf("got %q, %v, want the zero value and false", v, ok)
+		}
+	})
+}
+
+func TestWithTimeoutContext(t *testing.T) {
+	AssertNoLeaks(t)
+
+	t.Run("fast", func(t *testing.T) {
+		v, ok := WithTimeoutContext(context.Background(), time.Second, func(ctx context.Context) int { return 42 })
+		if !ok || v != 42 {
+			t.Errorf("got %d, %v, want 42, true", v, ok)
+		}
+	})
+
+	t.Run("slow", func(t *testing.T) {
+		cancelled := make(chan struct{})
+		v, ok := WithTimeoutContext(context.Background(), 20*time.Millisecond, func(ctx context.Context) int {
+			<-ctx.Done()
+			close(cancelled)
+			return 1
+		})
+		if ok || v != 0 {
+			t.Errorf("got %d, %v, want 0, false", v, ok)
+		}
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Error("fn's context was not cancelled")
+		}
+	})
+}
@@ -2,9 +2,53 @@ package main
 
 import (
 	"fmt"
+	"sync"
 	"time"
 )
 
+// Debounce returns a trigger that (re)starts a d timer, so fn runs once after
+// d has passed without another trigger. cancel drops any pending run and
+// turns later triggers into no-ops. Both are safe for concurrent use.
+func Debounce(d time.Duration, fn func()) (trigger func(), cancel func()) {
+	var mu sync.Mutex
+	var timer *time.Timer
+	var gen int
+	cancelled := false
+
+	trigger = func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if cancelled {
+			return
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		// a timer that already fired may be waiting on mu, the generation
+		// tells it that it has been superseded
+		gen++
+		mine := gen
+		timer = time.AfterFunc(d, func() {
+			mu.Lock()
+			stale := mine != gen || cancelled
+			mu.Unlock()
+			if !stale {
+				fn()
+			}
+		})
+	}
+
+	cancel = func() {
+		mu.Lock()
+		defer mu.Unlock()
+		cancelled = true
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+	return trigger, cancel
+}
+
 func main() {
 
 	timer1 := time.NewTimer(2 * time.Second)
@@ -23,4 +67,26 @@ func main() {
 	}
 
 	time.Sleep(2 * time.Second)
+
+	trigger, cancel := Debounce(100*time.Millisecond, func() {
+		fmt.Println("Debounced save")
+	})
+	for i := 0; i < 5; i++ {
+		trigger()
+		time.Sleep(20 * time.Millisecond)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	trigger()
+	cancel()
+	time.Sleep(200 * time.Millisecond)
+	fmt.Println("Debounce cancelled")
 }
+
+/*
+	$ go run timers.go
+	Timer 1 fired
+	Timer 2 stopped
+	Debounced save
+	Debounce cancelled
+*/
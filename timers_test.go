@@ -0,0 +1,48 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDebounce(t *testing.T) {
+	t.Run("rapid triggers run once", func(t *testing.T) {
+		var runs atomic.Int32
+		trigger, cancel := Debounce(50*time.Millisecond, func() { runs.Add(1) })
+		defer cancel()
+
+		var wg sync.WaitGroup
+		for g := 0; g < 10; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				trigger()
+			}()
+		}
+		wg.Wait()
+		for i := 0; i < 5; i++ {
+			trigger()
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		time.Sleep(150 * time.Millisecond)
+		if got := runs.Load(); got != 1 {
+			t.Errorf("fn ran %d times, want 1", got)
+		}
+	})
+
+	t.Run("cancel drops pending run", func(t *testing.T) {
+		var runs atomic.Int32
+		trigger, cancel := Debounce(50*time.Millisecond, func() { runs.Add(1) })
+		trigger()
+		cancel()
+		trigger()
+
+		time.Sleep(150 * time.Millisecond)
+		if got := runs.Load(); got != 0 {
+			t.Errorf("fn ran %d times after cancel, want 0", got)
+		}
+	})
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 )
 
@@ -14,6 +16,41 @@ func worker(id int, jobs <-chan int, results chan<- int) {
 	}
 }
 
+type Job func() (any, error)
+
+type Result struct {
+	Value any
+	Err   error
+}
+
+// RunPool runs jobs on at most workers goroutines and returns their results
+// in the same order as jobs. Fewer than one worker is treated as one.
+func RunPool(jobs []Job, workers int) []Result {
+	results := make([]Result, len(jobs))
+	workers = max(1, min(workers, len(jobs)))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				v, err := jobs[i]()
+				// each worker writes its own slot, so no lock is needed
+				results[i] = Result{Value: v, Err: err}
+			}
+		}()
+	}
+
+	for i := range jobs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
 func main() {
 
 	const numJobs = 5
@@ -32,4 +69,39 @@ func main() {
 	for a := 1; a <= numJobs; a++ {
 		<-results
 	}
+
+	fmt.Println()
+	var pool []Job
+	for j := 1; j <= numJobs; j++ {
+		pool = append(pool, func() (any, error) {
+			time.Sleep(time.Duration(numJobs-j) * 10 * time.Millisecond)
+			if j == 3 {
+				return nil, errors.New("job 3 failed")
+			}
+			return j * 2, nil
+		})
+	}
+	for i, r := range RunPool(pool, 3) {
+		fmt.Println("job", i+1, "=>", r.Value, r.Err)
+	}
 }
+
+/*
+	$ go run worker_pools.go
+	worker 3 started  job 1
+	worker 1 started  job 2
+	worker 2 started  job 3
+	worker 2 finished job 3
+	worker 2 started  job 4
+	worker 1 finished job 2
+	worker 1 started  job 5
+	worker 3 finished job 1
+	worker 1 finished job 5
+	worker 2 finished job 4
+
+	job 1 => 2 <nil>
+	job 2 => 4 <nil>
+	job 3 => <nil> job 3 failed
+	job 4 => 8 <nil>
+	job 5 => 10 <nil>
+*/
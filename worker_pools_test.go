@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunPool(t *testing.T) {
+	AssertNoLeaks(t)
+
+	errOdd := errors.New("odd job")
+	makeJobs := func(n int) []Job {
+		jobs := make([]Job, n)
+		for i := range jobs {
+			i := i
+			jobs[i] = func() (any, error) {
+				// later jobs finish first, so order has to be restored
+				time.Sleep(time.Duration(n-i) * time.Millisecond)
+				if i%2 == 1 {
+					return nil, errOdd
+				}
+				return i * 2, nil
+			}
+		}
+		return jobs
+	}
+
+	var tests = []struct {
+		name    string
+		jobs    int
+		workers int
+	}{
+		{"more jobs than workers", 10, 3},
+		{"more workers than jobs", 3, 10},
+		{"no workers", 4, 0},
+		{"no jobs", 0, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := RunPool(makeJobs(tt.jobs), tt.workers)
+			if len(results) != tt.jobs {
+				t.Fatalf("got %d results, want %d", len(results), tt.jobs)
+			}
+			for i, res := range results {
+				if i%2 == 1 {
+					if !errors.Is(res.Err, errOdd) {
+						t.Errorf("result %d got error %v, want %v", i, res.Err, errOdd)
+					}
+					continue
+				}
+				if res.Err != nil || res.Value != i*2 {
+					t.Errorf("result %d got %v, %v, want %d", i, res.Value, res.Err, i*2)
+				}
+			}
+		})
+	}
+}
+
+func TestRunPoolLimitsWorkers(t *testing.T) {
+	AssertNoLeaks(t)
+
+	var running, peak atomic.Int32
+	jobs := make([]Job, 12)
+	for i := range jobs {
+		jobs[i] = func() (any, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return nil, nil
+		}
+	}
+
+	RunPool(jobs, 3)
+	if got := peak.Load(); got > 3 {
+		t.Errorf("%d jobs ran at once, want at most 3", got)
+	}
+}
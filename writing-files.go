@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 func check(e error) {
@@ -13,6 +15,66 @@ func check(e error) {
 	}
 }
 
+// WriteFileAtomic writes data to a temp file next to path, syncs it and
+// renames it over path. Readers see either the old file or the complete new
+// one, never a partial write.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) (err error) {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(perm); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// sync the directory too, so the rename itself survives a crash
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// AppendLine appends line and a newline to the file at path, creating it if
+// needed. The line goes out in a single write on an O_APPEND file, so lines
+// from concurrent writers don't interleave.
+func AppendLine(path, line string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasSuffix(line, "\n") {
+		line += "\n"
+	}
+	if _, err := f.WriteString(line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 func main() {
 
 	d1 := []byte("hello\ngo\n")
@@ -41,4 +103,26 @@ func main() {
 	fmt.Printf("wrote %d bytes\n", n4)
 
 	w.Flush()
+
+	err = WriteFileAtomic("/tmp/dat3", []byte("all or nothing\n"), 0600)
+	check(err)
+	os.Remove("/tmp/dat4")
+	for _, line := range []string{"first", "second"} {
+		check(AppendLine("/tmp/dat4", line))
+	}
+	d3, err := os.ReadFile("/tmp/dat3")
+	check(err)
+	d4, err := os.ReadFile("/tmp/dat4")
+	check(err)
+	fmt.Print(string(d3), string(d4))
 }
+
+/*
+	$ go run writing-files.go
+	wrote 5 bytes
+	wrote 7 bytes
+	wrote 9 bytes
+	all or nothing
+	first
+	second
+*/
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFileAtomic(path, []byte("new"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Errorf("content: got %q, want %q", data, "new")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode().Perm(); got != 0600 {
+		t.Errorf("perm: got %v, want %v", got, os.FileMode(0600))
+	}
+
+	// the temp file was renamed, not left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d entries in the dir, want only config.json", len(entries))
+	}
+}
+
+func TestWriteFileAtomicMissingDir(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "config.json")
+	if err := WriteFileAtomic(path, []byte("x"), 0644); err == nil {
+		t.Error("got nil error, want one for a missing directory")
+	}
+}
+
+func TestAppendLineConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.txt")
+
+	const writers, perWriter = 10, 50
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				if err := AppendLine(path, fmt.Sprintf("writer %d line %d", w, i)); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != writers*perWriter {
+		t.Fatalf("got %d lines, want %d", len(lines), writers*perWriter)
+	}
+
+	// every line is whole and shows up exactly once
+	var want []string
+	for w := 0; w < writers; w++ {
+		for i := 0; i < perWriter; i++ {
+			want = append(want, fmt.Sprintf("writer %d line %d", w, i))
+		}
+	}
+	slices.Sort(lines)
+	slices.Sort(want)
+	if !slices.Equal(lines, want) {
+		t.Error("got interleaved or missing lines")
+	}
+}
+
+func TestAppendLineKeepsNewline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.txt")
+	for _, line := range []string{"one", "two\n"} {
+		if err := AppendLine(path, line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "one\ntwo\n"; string(data) != want {
+		t.Errorf("got %q, want %q", data, want)
+	}
+}
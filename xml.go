@@ -5,11 +5,20 @@ import (
 	"fmt"
 )
 
+const botanyNS = "http://example.com/botany"
+
+type Care struct {
+	Light string `xml:"light,attr"`
+	Water string `xml:"water"`
+}
+
 type Plant struct {
 	XMLName xml.Name `xml:"plant"`
 	Id      int      `xml:"id,attr"`
 	Name    string   `xml:"name"`
 	Origin  []string `xml:"origin"`
+	Latin   string   `xml:"http://example.com/botany latin,omitempty"`
+	Care    *Care    `xml:"care,omitempty"`
 }
 
 func (p Plant) String() string {
@@ -17,6 +26,27 @@ func (p Plant) String() string {
 		p.Id, p.Name, p.Origin)
 }
 
+// ToXML marshals v with the XML header, indented when pretty is set.
+func ToXML(v any, pretty bool) ([]byte, error) {
+	var out []byte
+	var err error
+	if pretty {
+		out, err = xml.MarshalIndent(v, "", "  ")
+	} else {
+		out, err = xml.Marshal(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), out...), nil
+}
+
+// FromXML unmarshals data into out. Elements out has no field for are
+// skipped, so documents with extra data still decode.
+func FromXML(data []byte, out any) error {
+	return xml.Unmarshal(data, out)
+}
+
 func main() {
 	coffee := &Plant{Id: 27, Name: "Coffee"}
 	coffee.Origin = []string{"Ethiopia", "Brazil"}
@@ -45,6 +75,24 @@ func main() {
 
 	out, _ = xml.MarshalIndent(nesting, " ", "  ")
 	fmt.Println(string(out))
+
+	basil := &Plant{Id: 9, Name: "Basil", Origin: []string{"India"}, Latin: "Ocimum basilicum",
+		Care: &Care{Light: "full sun", Water: "daily"}}
+	out, err := ToXML(basil, true)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(out))
+
+	extra := []byte(`<plant id="9" xmlns:b="` + botanyNS + `">
+		<name>Basil</name><b:latin>Ocimum basilicum</b:latin>
+		<height unit="cm">40</height><care light="shade"><water>weekly</water></care>
+	</plant>`)
+	var decoded Plant
+	if err := FromXML(extra, &decoded); err != nil {
+		panic(err)
+	}
+	fmt.Println(decoded, decoded.Latin, *decoded.Care)
 }
 
 /*
@@ -77,4 +125,14 @@ func main() {
 		</child>
 	</parent>
 	</nesting>
+	<?xml version="1.0" encoding="UTF-8"?>
+	<plant id="9">
+	  <name>Basil</name>
+	  <origin>India</origin>
+	  <latin xmlns="http://example.com/botany">Ocimum basilicum</latin>
+	  <care light="full sun">
+	    <water>daily</water>
+	  </care>
+	</plant>
+	Plant id=9, name=Basil, origin=[] Ocimum basilicum {shade weekly}
 */
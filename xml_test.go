@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlantRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		plant Plant
+	}{
+		{"minimal", Plant{Id: 27, Name: "Coffee"}},
+		{"origins", Plant{Id: 27, Name: "Coffee", Origin: []string{"Ethiopia", "Brazil"}}},
+		{"everything", Plant{
+			Id:     81,
+			Name:   "Tomato",
+			Origin: []string{"Mexico", "California"},
+			Latin:  "Solanum lycopersicum",
+			Care:   &Care{Light: "full sun", Water: "daily"},
+		}},
+	}
+	for _, tt := range tests {
+		for _, pretty := range []bool{false, true} {
+			data, err := ToXML(tt.plant, pretty)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(string(data), xml.Header) {
+				t.Errorf("%s: got %q, want it to start with the XML header", tt.name, data)
+			}
+
+			var got Plant
+			if err := FromXML(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			want := tt.plant
+			want.XMLName = xml.Name{Local: "plant"}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s, pretty %v: got %+v, want %+v", tt.name, pretty, got, want)
+			}
+		}
+	}
+}
+
+func TestFromXMLSkipsUnknownElements(t *testing.T) {
+	data := `<plant id="1"><name>Fern</name><height>30cm</height></plant>`
+	var got Plant
+	if err := FromXML([]byte(data), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Id != 1 || got.Name != "Fern" {
+		t.Errorf("got %v, want id=1 name=Fern", got)
+	}
+}